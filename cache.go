@@ -0,0 +1,210 @@
+package speedrail
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CacheEntry is the result of a strategy that is stored in a Cache.
+type CacheEntry[M any] struct {
+	Model M
+	Err   Error
+}
+
+// Cache is the storage used by Cached to keep results of strategies.
+type Cache[M any] interface {
+	// Get returns the entry stored for key, and whether it was found and not expired.
+	Get(key string) (CacheEntry[M], bool)
+	// Set stores the entry for key. A ttl of zero or less means that the entry never expires.
+	Set(key string, entry CacheEntry[M], ttl time.Duration)
+}
+
+// lruItem is an element stored in the LRUCache.
+type lruItem[M any] struct {
+	key       string
+	entry     CacheEntry[M]
+	expiresAt time.Time
+}
+
+// LRUCache is an in-memory Cache that evicts the least recently used entry when it is full.
+type LRUCache[M any] struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+// Type check that LRUCache implements Cache interface
+var _ Cache[any] = &LRUCache[any]{}
+
+// NewLRUCache will return an in-memory cache that holds at most capacity entries. A capacity of zero or less means that
+// the cache is unbounded.
+func NewLRUCache[M any](capacity int) Cache[M] {
+	return &LRUCache[M]{
+		capacity: capacity,
+		order:    list.New(),
+		items:    map[string]*list.Element{},
+	}
+}
+
+// Get returns the entry stored for key, and whether it was found and not expired.
+func (c *LRUCache[M]) Get(key string) (CacheEntry[M], bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		return CacheEntry[M]{}, false
+	}
+
+	item := element.Value.(lruItem[M])
+	if !item.expiresAt.IsZero() && time.Now().After(item.expiresAt) {
+		c.order.Remove(element)
+		delete(c.items, key)
+		return CacheEntry[M]{}, false
+	}
+
+	c.order.MoveToFront(element)
+	return item.entry, true
+}
+
+// Set stores the entry for key. A ttl of zero or less means that the entry never expires.
+func (c *LRUCache[M]) Set(key string, entry CacheEntry[M], ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item := lruItem[M]{key: key, entry: entry}
+	if ttl > 0 {
+		item.expiresAt = time.Now().Add(ttl)
+	}
+
+	if element, ok := c.items[key]; ok {
+		element.Value = item
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(item)
+	for c.capacity > 0 && c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(lruItem[M]).key)
+	}
+}
+
+// cacheConfig holds the settings of a Cached strategy.
+type cacheConfig struct {
+	negativeStatusCodes map[int]bool
+}
+
+// CacheOption configures the behaviour of Cached.
+type CacheOption func(*cacheConfig)
+
+// CacheErrors will enable negative caching, errors with any of the given status codes are cached just like successful
+// results.
+func CacheErrors(statusCodes ...int) CacheOption {
+	return func(config *cacheConfig) {
+		for _, statusCode := range statusCodes {
+			config.negativeStatusCodes[statusCode] = true
+		}
+	}
+}
+
+// ErrStrategyPanicked is the error returned to executions of Cached that waited for a strategy that panicked.
+var ErrStrategyPanicked = errors.New("strategy panicked")
+
+// flight is a call in progress, or completed, for a key in a flightGroup.
+type flight[M any] struct {
+	done      chan struct{}
+	entry     CacheEntry[M]
+	completed bool
+}
+
+// flightGroup deduplicates concurrent calls with the same key, so that only one of them is executed.
+type flightGroup[M any] struct {
+	mu      sync.Mutex
+	flights map[string]*flight[M]
+}
+
+// do executes fn for key, unless it is already being executed in which case the result of that call is returned. If fn
+// panics, the panic is passed on to the caller that executed it, and the callers that waited for it receive an error.
+func (g *flightGroup[M]) do(key string, fn func() CacheEntry[M]) (CacheEntry[M], bool, Error) {
+	g.mu.Lock()
+	if current, ok := g.flights[key]; ok {
+		g.mu.Unlock()
+		<-current.done
+		if !current.completed {
+			return CacheEntry[M]{}, true, NewError(ErrStrategyPanicked, http.StatusInternalServerError, "strategy panicked")
+		}
+
+		return current.entry, true, nil
+	}
+
+	current := &flight[M]{done: make(chan struct{})}
+	g.flights[key] = current
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.flights, key)
+		g.mu.Unlock()
+		close(current.done)
+	}()
+
+	current.entry = fn()
+	current.completed = true
+	return current.entry, false, nil
+}
+
+// Cached executes a strategy and stores its resulting model in cache under the key derived from the model. When the
+// cache already holds a result for the key, the strategy is not executed and the cached model is applied to the model
+// with apply, which copies the fields that the strategy sets, so that the fields that are not part of the key are kept.
+// Concurrent executions with the same key are deduplicated, so that only one of them executes the strategy. Errors are
+// not cached unless enabled with CacheErrors. An empty key will bypass the cache.
+func Cached[C, M any](cache Cache[M], key func(M) string, apply func(current, cached M) M, strategy Strategy[C, M], ttl time.Duration, options ...CacheOption) Strategy[C, M] {
+	config := cacheConfig{negativeStatusCodes: map[int]bool{}}
+	for _, option := range options {
+		option(&config)
+	}
+
+	problems := append(nilProblem("cache", cache == nil), nilProblem("key", key == nil)...)
+	problems = append(problems, nilProblem("apply", apply == nil)...)
+	problems = append(problems, namedProblems("strategy", strategy)...)
+
+	group := &flightGroup[M]{flights: map[string]*flight[M]{}}
+	return validated("Cached", problems, func(ctx context.Context, container C, model M) (context.Context, M, Error) {
+		cacheKey := key(model)
+		if cacheKey == "" {
+			return run(ctx, strategy, container, model)
+		}
+
+		if entry, ok := cache.Get(cacheKey); ok {
+			return ctx, apply(model, entry.Model), entry.Err
+		}
+
+		resultCtx := ctx
+		entry, shared, err := group.do(cacheKey, func() CacheEntry[M] {
+			var entry CacheEntry[M]
+			resultCtx, entry.Model, entry.Err = run(ctx, strategy, container, model)
+			if entry.Err == nil || config.negativeStatusCodes[entry.Err.StatusCode()] {
+				cache.Set(cacheKey, entry, ttl)
+			}
+
+			return entry
+		})
+
+		if err != nil {
+			return ctx, model, err
+		}
+
+		if shared {
+			return ctx, apply(model, entry.Model), entry.Err
+		}
+
+		return resultCtx, entry.Model, entry.Err
+	})
+}
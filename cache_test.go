@@ -0,0 +1,208 @@
+package speedrail_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Kansuler/speedrail"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type SpeedrailCacheTestSuite struct {
+	suite.Suite
+}
+
+type cacheTestModel struct {
+	ID   string
+	Name string
+}
+
+func cacheTestKey(model cacheTestModel) string {
+	return model.ID
+}
+
+func cacheTestApply(current, cached cacheTestModel) cacheTestModel {
+	current.Name = cached.Name
+	return current
+}
+
+func (suite *SpeedrailCacheTestSuite) TestCached() {
+	var calls int32
+	cache := speedrail.NewLRUCache[cacheTestModel](10)
+	plan := speedrail.Plan(
+		speedrail.Cached(cache, cacheTestKey, cacheTestApply, func(ctx context.Context, container any, model cacheTestModel) (context.Context, cacheTestModel, speedrail.Error) {
+			atomic.AddInt32(&calls, 1)
+			model.Name = "name of " + model.ID
+			return ctx, model, nil
+		}, time.Minute),
+	)
+
+	_, model, err := plan.Execute(context.Background(), nil, cacheTestModel{ID: "1"})
+	suite.NoError(err)
+	suite.Equal("name of 1", model.Name)
+
+	_, model, err = plan.Execute(context.Background(), nil, cacheTestModel{ID: "1"})
+	suite.NoError(err)
+	suite.Equal("name of 1", model.Name)
+	suite.Equal(int32(1), atomic.LoadInt32(&calls))
+
+	_, model, err = plan.Execute(context.Background(), nil, cacheTestModel{ID: "2"})
+	suite.NoError(err)
+	suite.Equal("name of 2", model.Name)
+	suite.Equal(int32(2), atomic.LoadInt32(&calls))
+
+	_, _, err = plan.Execute(context.Background(), nil, cacheTestModel{})
+	suite.NoError(err)
+	_, _, err = plan.Execute(context.Background(), nil, cacheTestModel{})
+	suite.NoError(err)
+	suite.Equal(int32(4), atomic.LoadInt32(&calls))
+}
+
+func (suite *SpeedrailCacheTestSuite) TestNegativeCaching() {
+	var calls int32
+	strategy := func(ctx context.Context, container any, model cacheTestModel) (context.Context, cacheTestModel, speedrail.Error) {
+		atomic.AddInt32(&calls, 1)
+		if model.ID == "missing" {
+			return ctx, model, speedrail.NewError(errors.New("not found"), http.StatusNotFound, "not found")
+		}
+
+		return ctx, model, speedrail.NewError(errors.New("unavailable"), http.StatusServiceUnavailable, "unavailable")
+	}
+
+	plan := speedrail.Plan(
+		speedrail.Cached(speedrail.NewLRUCache[cacheTestModel](10), cacheTestKey, cacheTestApply, strategy, time.Minute, speedrail.CacheErrors(http.StatusNotFound)),
+	)
+
+	for i := 0; i < 2; i++ {
+		_, _, err := plan.Execute(context.Background(), nil, cacheTestModel{ID: "missing"})
+		suite.Error(err)
+		suite.Equal(http.StatusNotFound, err.StatusCode())
+	}
+	suite.Equal(int32(1), atomic.LoadInt32(&calls))
+
+	for i := 0; i < 2; i++ {
+		_, _, err := plan.Execute(context.Background(), nil, cacheTestModel{ID: "down"})
+		suite.Error(err)
+		suite.Equal(http.StatusServiceUnavailable, err.StatusCode())
+	}
+	suite.Equal(int32(3), atomic.LoadInt32(&calls))
+}
+
+func (suite *SpeedrailCacheTestSuite) TestSingleflight() {
+	var calls int32
+	release := make(chan struct{})
+	plan := speedrail.Plan(
+		speedrail.Cached(speedrail.NewLRUCache[cacheTestModel](10), cacheTestKey, cacheTestApply, func(ctx context.Context, container any, model cacheTestModel) (context.Context, cacheTestModel, speedrail.Error) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			model.Name = "shared"
+			return ctx, model, nil
+		}, time.Minute),
+	)
+
+	var wg sync.WaitGroup
+	models := make([]cacheTestModel, 5)
+	for i := range models {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, models[i], _ = plan.Execute(context.Background(), nil, cacheTestModel{ID: "1"})
+		}(i)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	suite.Equal(int32(1), atomic.LoadInt32(&calls))
+	for _, model := range models {
+		suite.Equal("shared", model.Name)
+	}
+}
+
+func (suite *SpeedrailCacheTestSuite) TestApply() {
+	type model struct {
+		Key    string
+		Caller int
+		Result int
+	}
+
+	cache := speedrail.NewLRUCache[model](10)
+	plan := speedrail.Plan(
+		speedrail.Cached(cache, func(m model) string { return m.Key }, func(current, cached model) model {
+			current.Result = cached.Result
+			return current
+		}, func(ctx context.Context, container any, m model) (context.Context, model, speedrail.Error) {
+			m.Result = 42
+			return ctx, m, nil
+		}, time.Minute),
+	)
+
+	_, result, err := plan.Execute(context.Background(), nil, model{Key: "k", Caller: 1})
+	suite.NoError(err)
+	suite.Equal(model{Key: "k", Caller: 1, Result: 42}, result)
+
+	_, result, err = plan.Execute(context.Background(), nil, model{Key: "k", Caller: 2})
+	suite.NoError(err)
+	suite.Equal(model{Key: "k", Caller: 2, Result: 42}, result)
+}
+
+func (suite *SpeedrailCacheTestSuite) TestSingleflightPanic() {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	plan := speedrail.Plan(
+		speedrail.Cached(speedrail.NewLRUCache[cacheTestModel](10), cacheTestKey, cacheTestApply, func(ctx context.Context, container any, model cacheTestModel) (context.Context, cacheTestModel, speedrail.Error) {
+			close(started)
+			<-release
+			panic("failed")
+		}, time.Minute),
+	)
+
+	go func() {
+		defer func() { _ = recover() }()
+		_, _, _ = plan.Execute(context.Background(), nil, cacheTestModel{ID: "1"})
+	}()
+
+	<-started
+	done := make(chan speedrail.Error)
+	go func() {
+		_, _, err := plan.Execute(context.Background(), nil, cacheTestModel{ID: "1"})
+		done <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	err := <-done
+	suite.ErrorIs(err, speedrail.ErrStrategyPanicked)
+	suite.Equal(http.StatusInternalServerError, err.StatusCode())
+}
+
+func (suite *SpeedrailCacheTestSuite) TestLRUCache() {
+	cache := speedrail.NewLRUCache[int](2)
+	cache.Set("a", speedrail.CacheEntry[int]{Model: 1}, 0)
+	cache.Set("b", speedrail.CacheEntry[int]{Model: 2}, 0)
+
+	entry, ok := cache.Get("a")
+	suite.True(ok)
+	suite.Equal(1, entry.Model)
+
+	cache.Set("c", speedrail.CacheEntry[int]{Model: 3}, 0)
+	_, ok = cache.Get("b")
+	suite.False(ok)
+	_, ok = cache.Get("a")
+	suite.True(ok)
+
+	cache.Set("d", speedrail.CacheEntry[int]{Model: 4}, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	_, ok = cache.Get("d")
+	suite.False(ok)
+}
+
+func TestSpeedrailCacheTestSuite(t *testing.T) {
+	suite.Run(t, new(SpeedrailCacheTestSuite))
+}
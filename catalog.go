@@ -0,0 +1,112 @@
+package speedrail
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// Catalog is a Translator that holds message templates per locale. Templates use the syntax of text/template, with the
+// arguments of the message as data, such as "Användaren {{.email}} finns redan". A template that refers to an argument
+// that is not given has no translation, so that the untranslated message is used instead.
+type Catalog struct {
+	defaultLocale string
+	messages      map[string]map[string]*template.Template
+}
+
+// Type check that Catalog implements Translator interface
+var _ Translator = (*Catalog)(nil)
+
+// NewCatalog will return an empty catalog, where defaultLocale is used when no preferred locale has a translation.
+func NewCatalog(defaultLocale string) *Catalog {
+	return &Catalog{
+		defaultLocale: normalizeLocale(defaultLocale),
+		messages:      map[string]map[string]*template.Template{},
+	}
+}
+
+// LoadCatalog will return a catalog with the JSON files in fsys that match pattern, such as "locales/*.json". Every file
+// holds the messages of one locale as an object of keys and templates, and the name of the file without extension is
+// the locale, such as sv.json or en-US.json.
+func LoadCatalog(fsys fs.FS, pattern string, defaultLocale string) (*Catalog, error) {
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := NewCatalog(defaultLocale)
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		var messages map[string]string
+		if err = json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("could not decode catalog %s: %w", name, err)
+		}
+
+		if err = catalog.Add(strings.TrimSuffix(path.Base(name), path.Ext(name)), messages); err != nil {
+			return nil, fmt.Errorf("could not load catalog %s: %w", name, err)
+		}
+	}
+
+	return catalog, nil
+}
+
+// Add will add the message templates of a locale to the catalog, replacing templates with the same key.
+func (c *Catalog) Add(locale string, messages map[string]string) error {
+	locale = normalizeLocale(locale)
+	if c.messages[locale] == nil {
+		c.messages[locale] = map[string]*template.Template{}
+	}
+
+	for key, message := range messages {
+		tmpl, err := template.New(key).Option("missingkey=error").Parse(message)
+		if err != nil {
+			return err
+		}
+
+		c.messages[locale][key] = tmpl
+	}
+
+	return nil
+}
+
+// Translate will return the message with key in locale, or in the language of locale if there is no message for the
+// region, such as sv for sv-SE. An empty locale results in the default locale of the catalog.
+func (c *Catalog) Translate(locale, key string, args map[string]any) (string, bool) {
+	locale = normalizeLocale(locale)
+	if locale == "" {
+		locale = c.defaultLocale
+	}
+
+	tmpl, ok := c.messages[locale][key]
+	if !ok {
+		language, _, found := strings.Cut(locale, "-")
+		if !found {
+			return "", false
+		}
+
+		if tmpl, ok = c.messages[language][key]; !ok {
+			return "", false
+		}
+	}
+
+	var message bytes.Buffer
+	if err := tmpl.Execute(&message, args); err != nil {
+		return "", false
+	}
+
+	return message.String(), true
+}
+
+// normalizeLocale lower cases locale and separates the language and region with a hyphen, so that sv_SE and sv-se are
+// the same locale.
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
@@ -0,0 +1,131 @@
+package speedrail
+
+import (
+	"net/http"
+)
+
+// Category is the class of an error, which decides its default status code and if it is worth retrying.
+type Category string
+
+const (
+	// CategoryValidation is the category of errors caused by invalid input.
+	CategoryValidation Category = "validation"
+	// CategoryNotFound is the category of errors caused by a resource that does not exist.
+	CategoryNotFound Category = "not_found"
+	// CategoryConflict is the category of errors caused by a conflict with the state of a resource.
+	CategoryConflict Category = "conflict"
+	// CategoryUnauthorized is the category of errors caused by missing or invalid credentials.
+	CategoryUnauthorized Category = "unauthorized"
+	// CategoryTransient is the category of temporary errors, such as an unavailable dependency, that may succeed if
+	// retried.
+	CategoryTransient Category = "transient"
+	// CategoryInternal is the category of unexpected errors.
+	CategoryInternal Category = "internal"
+)
+
+// StatusCode returns the default status code of the category. Errors without a category are internal server errors.
+func (c Category) StatusCode() int {
+	switch c {
+	case CategoryValidation:
+		return http.StatusBadRequest
+	case CategoryNotFound:
+		return http.StatusNotFound
+	case CategoryConflict:
+		return http.StatusConflict
+	case CategoryUnauthorized:
+		return http.StatusUnauthorized
+	case CategoryTransient:
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusInternalServerError
+}
+
+// Retryable reports if errors of the category may succeed if retried.
+func (c Category) Retryable() bool {
+	return c == CategoryTransient
+}
+
+// WithCategory sets the category of the error.
+func WithCategory(category Category) ErrorOption {
+	return func(entry *ErrorWithTrail) {
+		entry.Category = category
+	}
+}
+
+// Validation will return an error in the validation category, with status code 400.
+func Validation(err error, outgoingMessage string, options ...ErrorOption) Error {
+	return newCategoryError(CategoryValidation, err, outgoingMessage, options)
+}
+
+// NotFound will return an error in the not found category, with status code 404.
+func NotFound(err error, outgoingMessage string, options ...ErrorOption) Error {
+	return newCategoryError(CategoryNotFound, err, outgoingMessage, options)
+}
+
+// Conflict will return an error in the conflict category, with status code 409.
+func Conflict(err error, outgoingMessage string, options ...ErrorOption) Error {
+	return newCategoryError(CategoryConflict, err, outgoingMessage, options)
+}
+
+// Unauthorized will return an error in the unauthorized category, with status code 401.
+func Unauthorized(err error, outgoingMessage string, options ...ErrorOption) Error {
+	return newCategoryError(CategoryUnauthorized, err, outgoingMessage, options)
+}
+
+// Transient will return an error in the transient category, with status code 503.
+func Transient(err error, outgoingMessage string, options ...ErrorOption) Error {
+	return newCategoryError(CategoryTransient, err, outgoingMessage, options)
+}
+
+// Internal will return an error in the internal category, with status code 500.
+func Internal(err error, outgoingMessage string, options ...ErrorOption) Error {
+	return newCategoryError(CategoryInternal, err, outgoingMessage, options)
+}
+
+// newCategoryError will return an error with the category and its default status code, the name of the caller of the
+// exported constructor is used as strategy name.
+func newCategoryError(category Category, err error, outgoingMessage string, options []ErrorOption) Error {
+	options = append([]ErrorOption{WithCategory(category)}, options...)
+	return newError(callerName(3), captureStack(3), err, category.StatusCode(), outgoingMessage, options...)
+}
+
+// CategoryOf returns the category of err, which is the category of the first entry in the trail with the status code of
+// err, or otherwise of the first entry that has a category. An empty category is returned if no entry has one.
+func CategoryOf(err Error) Category {
+	var category Category
+	for _, entry := range err.Trail() {
+		if entry.Category == "" {
+			continue
+		}
+
+		if entry.StatusCode == err.StatusCode() {
+			return entry.Category
+		}
+
+		if category == "" {
+			category = entry.Category
+		}
+	}
+
+	return category
+}
+
+// Retryable reports if err may succeed if retried, which is the case when every entry in its trail with a category has a
+// retryable category. Errors without a category are not retryable.
+func Retryable(err Error) bool {
+	retryable := false
+	for _, entry := range err.Trail() {
+		if entry.Category == "" {
+			continue
+		}
+
+		if !entry.Category.Retryable() {
+			return false
+		}
+
+		retryable = true
+	}
+
+	return retryable
+}
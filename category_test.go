@@ -0,0 +1,98 @@
+package speedrail_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/Kansuler/speedrail"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type SpeedrailCategoryTestSuite struct {
+	suite.Suite
+}
+
+func (suite *SpeedrailCategoryTestSuite) TestConstructors() {
+	for _, test := range []struct {
+		err        speedrail.Error
+		category   speedrail.Category
+		statusCode int
+		retryable  bool
+	}{
+		{speedrail.Validation(nil, "invalid"), speedrail.CategoryValidation, http.StatusBadRequest, false},
+		{speedrail.NotFound(nil, "not found"), speedrail.CategoryNotFound, http.StatusNotFound, false},
+		{speedrail.Conflict(nil, "conflict"), speedrail.CategoryConflict, http.StatusConflict, false},
+		{speedrail.Unauthorized(nil, "unauthorized"), speedrail.CategoryUnauthorized, http.StatusUnauthorized, false},
+		{speedrail.Transient(nil, "unavailable"), speedrail.CategoryTransient, http.StatusServiceUnavailable, true},
+		{speedrail.Internal(nil, "internal"), speedrail.CategoryInternal, http.StatusInternalServerError, false},
+	} {
+		suite.Equal(test.category, speedrail.CategoryOf(test.err))
+		suite.Equal(test.statusCode, test.err.StatusCode())
+		suite.Equal(test.retryable, speedrail.Retryable(test.err))
+		suite.Equal(test.retryable, test.category.Retryable())
+		suite.Equal("github.com/Kansuler/speedrail_test.(*SpeedrailCategoryTestSuite).TestConstructors", test.err.Trail()[0].StrategyName)
+	}
+
+	err := speedrail.NotFound(errors.New("no rows"), "user not found", speedrail.WithCode("USER_NOT_FOUND"), speedrail.WithStack())
+	suite.Equal("USER_NOT_FOUND", speedrail.ErrorCode(err))
+	suite.Require().NotEmpty(err.Trail()[0].Stack)
+	suite.Equal("github.com/Kansuler/speedrail_test.(*SpeedrailCategoryTestSuite).TestConstructors", err.Trail()[0].Stack[0].Function)
+
+	err = speedrail.NewError(nil, http.StatusTeapot, "teapot", speedrail.WithCategory(speedrail.CategoryValidation))
+	suite.Equal(http.StatusTeapot, err.StatusCode())
+	suite.Equal(speedrail.CategoryValidation, speedrail.CategoryOf(err))
+
+	uncategorized := speedrail.NewError(nil, http.StatusServiceUnavailable, "unavailable")
+	suite.Equal(speedrail.Category(""), speedrail.CategoryOf(uncategorized))
+	suite.False(speedrail.Retryable(uncategorized))
+	suite.Equal(http.StatusInternalServerError, speedrail.Category("").StatusCode())
+}
+
+func (suite *SpeedrailCategoryTestSuite) TestMerge() {
+	err := speedrail.Validation(nil, "invalid").Merge(speedrail.Transient(nil, "unavailable"))
+	suite.Equal(http.StatusServiceUnavailable, err.StatusCode())
+	suite.Equal(speedrail.CategoryTransient, speedrail.CategoryOf(err))
+	suite.False(speedrail.Retryable(err))
+
+	err = speedrail.Transient(nil, "unavailable").Merge(speedrail.NewError(nil, http.StatusBadGateway, "bad gateway"))
+	suite.Equal(speedrail.CategoryTransient, speedrail.CategoryOf(err))
+	suite.True(speedrail.Retryable(err))
+
+	data, marshalErr := json.Marshal(err.Trail())
+	suite.NoError(marshalErr)
+	var trail speedrail.Trail
+	suite.NoError(json.Unmarshal(data, &trail))
+	suite.Equal(speedrail.CategoryTransient, trail[0].Category)
+	suite.Equal(speedrail.CategoryTransient, speedrail.Public(err).Trail()[0].Category)
+}
+
+func (suite *SpeedrailCategoryTestSuite) TestHedge() {
+	for _, test := range []struct {
+		err   speedrail.Error
+		calls int32
+	}{
+		{speedrail.Validation(nil, "invalid"), 1},
+		{speedrail.Transient(nil, "unavailable"), 2},
+		{speedrail.NewError(nil, http.StatusInternalServerError, "uncategorized"), 1},
+	} {
+		var calls int32
+		plan := speedrail.Plan(
+			speedrail.Hedge(time.Second, func(ctx context.Context, container any, model any) (context.Context, any, speedrail.Error) {
+				atomic.AddInt32(&calls, 1)
+				return ctx, model, test.err
+			}),
+		)
+
+		_, _, err := plan.Execute(context.Background(), nil, nil)
+		suite.Error(err)
+		suite.Equal(test.calls, atomic.LoadInt32(&calls))
+	}
+}
+
+func TestSpeedrailCategoryTestSuite(t *testing.T) {
+	suite.Run(t, new(SpeedrailCategoryTestSuite))
+}
@@ -0,0 +1,243 @@
+package speedrail
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrNoCheckpoint is the error returned when there is no checkpoint to resume from.
+var ErrNoCheckpoint = errors.New("no checkpoint to resume from")
+
+// ErrCheckpointMismatch is the error returned when a checkpoint does not match the plan that is resumed.
+var ErrCheckpointMismatch = errors.New("checkpoint does not match plan")
+
+// ErrCheckpointStore is the error returned when the checkpoint store fails.
+var ErrCheckpointStore = errors.New("checkpoint store failed")
+
+// Checkpoint is the state of a plan after a completed strategy.
+type Checkpoint[M any] struct {
+	// Completed is the number of strategies in the plan that have completed.
+	Completed int
+	// Strategies is the number of strategies in the plan, and is used to detect that the plan has changed.
+	Strategies int
+	// Model is the model returned by the last completed strategy.
+	Model M
+}
+
+// CheckpointStore persists checkpoints of executing plans.
+type CheckpointStore[M any] interface {
+	// Save stores the checkpoint for id, replacing any previous checkpoint.
+	Save(ctx context.Context, id string, checkpoint Checkpoint[M]) error
+	// Load returns the checkpoint for id, and false if there is none.
+	Load(ctx context.Context, id string) (Checkpoint[M], bool, error)
+	// Delete removes the checkpoint for id.
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryCheckpointStore is an in-memory CheckpointStore.
+type MemoryCheckpointStore[M any] struct {
+	mu          sync.Mutex
+	checkpoints map[string]Checkpoint[M]
+}
+
+// Type check that MemoryCheckpointStore implements CheckpointStore interface
+var _ CheckpointStore[any] = &MemoryCheckpointStore[any]{}
+
+// NewMemoryCheckpointStore will return an in-memory checkpoint store.
+func NewMemoryCheckpointStore[M any]() CheckpointStore[M] {
+	return &MemoryCheckpointStore[M]{checkpoints: map[string]Checkpoint[M]{}}
+}
+
+// Save stores the checkpoint for id, replacing any previous checkpoint.
+func (s *MemoryCheckpointStore[M]) Save(_ context.Context, id string, checkpoint Checkpoint[M]) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[id] = checkpoint
+	return nil
+}
+
+// Load returns the checkpoint for id, and false if there is none.
+func (s *MemoryCheckpointStore[M]) Load(_ context.Context, id string) (Checkpoint[M], bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkpoint, ok := s.checkpoints[id]
+	return checkpoint, ok, nil
+}
+
+// Delete removes the checkpoint for id.
+func (s *MemoryCheckpointStore[M]) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.checkpoints, id)
+	return nil
+}
+
+// fileCheckpoint is the format of a checkpoint stored by FileCheckpointStore.
+type fileCheckpoint struct {
+	Completed  int    `json:"completed"`
+	Strategies int    `json:"strategies"`
+	Model      []byte `json:"model"`
+}
+
+// FileCheckpointStore is a CheckpointStore that keeps one file per checkpoint in a directory.
+type FileCheckpointStore[M any] struct {
+	dir   string
+	codec Codec[M]
+}
+
+// Type check that FileCheckpointStore implements CheckpointStore interface
+var _ CheckpointStore[any] = &FileCheckpointStore[any]{}
+
+// NewFileCheckpointStore will return a checkpoint store that writes checkpoints to dir, with models serialized by codec.
+func NewFileCheckpointStore[M any](dir string, codec Codec[M]) (CheckpointStore[M], error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &FileCheckpointStore[M]{dir: dir, codec: codec}, nil
+}
+
+// path returns the path of the file for the checkpoint with id.
+func (s *FileCheckpointStore[M]) path(id string) string {
+	return filepath.Join(s.dir, url.PathEscape(id)+".checkpoint")
+}
+
+// Save stores the checkpoint for id, replacing any previous checkpoint. The file is replaced atomically, so that a
+// crash while saving never leaves a partial checkpoint behind.
+func (s *FileCheckpointStore[M]) Save(_ context.Context, id string, checkpoint Checkpoint[M]) error {
+	model, err := s.codec.Marshal(checkpoint.Model)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(fileCheckpoint{
+		Completed:  checkpoint.Completed,
+		Strategies: checkpoint.Strategies,
+		Model:      model,
+	})
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(s.dir, ".checkpoint-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), s.path(id))
+}
+
+// Load returns the checkpoint for id, and false if there is none.
+func (s *FileCheckpointStore[M]) Load(_ context.Context, id string) (Checkpoint[M], bool, error) {
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return Checkpoint[M]{}, false, nil
+	}
+
+	if err != nil {
+		return Checkpoint[M]{}, false, err
+	}
+
+	var stored fileCheckpoint
+	if err = json.Unmarshal(data, &stored); err != nil {
+		return Checkpoint[M]{}, false, err
+	}
+
+	model, err := s.codec.Unmarshal(stored.Model)
+	if err != nil {
+		return Checkpoint[M]{}, false, err
+	}
+
+	return Checkpoint[M]{Completed: stored.Completed, Strategies: stored.Strategies, Model: model}, true, nil
+}
+
+// Delete removes the checkpoint for id.
+func (s *FileCheckpointStore[M]) Delete(_ context.Context, id string) error {
+	err := os.Remove(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// checkpointStoreError will return a speedrail error for a failing checkpoint store.
+func checkpointStoreError(err error) Error {
+	return NewError(errors.Join(ErrCheckpointStore, err), http.StatusInternalServerError, "checkpoint store failed")
+}
+
+// ExecuteWithCheckpoints executes the plan like Execute, and saves a checkpoint with the model to store after every
+// completed strategy. The checkpoint is deleted when the plan completes successfully, and kept when a strategy returns
+// an error so that the execution can be resumed with Resume. Values stored in the context are not persisted.
+func (s Speedrail[C, M]) ExecuteWithCheckpoints(ctx context.Context, store CheckpointStore[M], id string, container C, model M) (context.Context, M, Error) {
+	if s == nil {
+		return ctx, model, NewError(ErrNoStrategy, http.StatusInternalServerError, "no strategies to execute")
+	}
+
+	return s.executeWithCheckpoints(ctx, store, id, container, model, 0)
+}
+
+// Resume continues the execution of the plan from the checkpoint with id in store, with the model of the checkpoint.
+func (s Speedrail[C, M]) Resume(ctx context.Context, store CheckpointStore[M], id string, container C) (context.Context, M, Error) {
+	var model M
+	checkpoint, ok, err := store.Load(ctx, id)
+	if err != nil {
+		return ctx, model, checkpointStoreError(err)
+	}
+
+	if !ok {
+		return ctx, model, NewError(ErrNoCheckpoint, http.StatusNotFound, "no checkpoint to resume from")
+	}
+
+	if checkpoint.Strategies != len(s) || checkpoint.Completed > len(s) {
+		return ctx, checkpoint.Model, NewError(
+			fmt.Errorf("%w: checkpoint has %d strategies, plan has %d", ErrCheckpointMismatch, checkpoint.Strategies, len(s)),
+			http.StatusInternalServerError,
+			"checkpoint does not match plan",
+		)
+	}
+
+	return s.executeWithCheckpoints(ctx, store, id, container, checkpoint.Model, checkpoint.Completed)
+}
+
+// executeWithCheckpoints executes the plan from index start, and saves a checkpoint after every completed strategy.
+func (s Speedrail[C, M]) executeWithCheckpoints(ctx context.Context, store CheckpointStore[M], id string, container C, model M, start int) (context.Context, M, Error) {
+	ctx, model, resultErr := s.execute(ctx, container, model, start, func(index int, model M) Error {
+		if err := store.Save(ctx, id, Checkpoint[M]{Completed: index + 1, Strategies: len(s), Model: model}); err != nil {
+			return checkpointStoreError(err)
+		}
+
+		return nil
+	})
+	if resultErr != nil {
+		return ctx, model, resultErr
+	}
+
+	if err := store.Delete(ctx, id); err != nil {
+		return ctx, model, checkpointStoreError(err)
+	}
+
+	return ctx, model, nil
+}
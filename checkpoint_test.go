@@ -0,0 +1,85 @@
+package speedrail_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Kansuler/speedrail"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"testing"
+)
+
+type SpeedrailCheckpointTestSuite struct {
+	suite.Suite
+}
+
+type checkpointTestModel struct {
+	Steps []string `json:"steps"`
+}
+
+func checkpointTestStep(name string, fail *bool) speedrail.Strategy[any, checkpointTestModel] {
+	return func(ctx context.Context, container any, model checkpointTestModel) (context.Context, checkpointTestModel, speedrail.Error) {
+		if fail != nil && *fail {
+			return ctx, model, speedrail.NewError(errors.New("crash"), http.StatusServiceUnavailable, "crash")
+		}
+
+		model.Steps = append(model.Steps, name)
+		return ctx, model, nil
+	}
+}
+
+func (suite *SpeedrailCheckpointTestSuite) testResume(store speedrail.CheckpointStore[checkpointTestModel]) {
+	fail := true
+	plan := speedrail.Plan(
+		checkpointTestStep("first", nil),
+		checkpointTestStep("second", nil),
+		checkpointTestStep("third", &fail),
+	)
+
+	_, _, err := plan.ExecuteWithCheckpoints(context.Background(), store, "execution", nil, checkpointTestModel{})
+	suite.Error(err)
+
+	checkpoint, ok, loadErr := store.Load(context.Background(), "execution")
+	suite.NoError(loadErr)
+	suite.True(ok)
+	suite.Equal(2, checkpoint.Completed)
+	suite.Equal(3, checkpoint.Strategies)
+	suite.Equal([]string{"first", "second"}, checkpoint.Model.Steps)
+
+	fail = false
+	_, model, err := plan.Resume(context.Background(), store, "execution", nil)
+	suite.NoError(err)
+	suite.Equal([]string{"first", "second", "third"}, model.Steps)
+
+	_, ok, loadErr = store.Load(context.Background(), "execution")
+	suite.NoError(loadErr)
+	suite.False(ok)
+
+	_, _, err = plan.Resume(context.Background(), store, "execution", nil)
+	suite.Error(err)
+	suite.ErrorIs(err, speedrail.ErrNoCheckpoint)
+}
+
+func (suite *SpeedrailCheckpointTestSuite) TestMemoryCheckpointStore() {
+	suite.testResume(speedrail.NewMemoryCheckpointStore[checkpointTestModel]())
+}
+
+func (suite *SpeedrailCheckpointTestSuite) TestFileCheckpointStore() {
+	store, err := speedrail.NewFileCheckpointStore[checkpointTestModel](suite.T().TempDir(), speedrail.JSONCodec[checkpointTestModel]{})
+	suite.Require().NoError(err)
+	suite.testResume(store)
+}
+
+func (suite *SpeedrailCheckpointTestSuite) TestCheckpointMismatch() {
+	store := speedrail.NewMemoryCheckpointStore[checkpointTestModel]()
+	suite.NoError(store.Save(context.Background(), "execution", speedrail.Checkpoint[checkpointTestModel]{Completed: 1, Strategies: 3}))
+
+	plan := speedrail.Plan(checkpointTestStep("first", nil))
+	_, _, err := plan.Resume(context.Background(), store, "execution", nil)
+	suite.Error(err)
+	suite.ErrorIs(err, speedrail.ErrCheckpointMismatch)
+}
+
+func TestSpeedrailCheckpointTestSuite(t *testing.T) {
+	suite.Run(t, new(SpeedrailCheckpointTestSuite))
+}
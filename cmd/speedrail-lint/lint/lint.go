@@ -0,0 +1,302 @@
+// Package lint is a static analyzer that reports common misuse of speedrail, such as strategies that return a nil
+// context, models with pointer fields, and strategies that can never be executed. It can be run with go vet through the
+// speedrail-lint command.
+package lint
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ast/inspector"
+	"strings"
+)
+
+// speedrailPath is the import path of the speedrail package.
+const speedrailPath = "github.com/Kansuler/speedrail"
+
+// Analyzer reports common misuse of speedrail:
+//   - strategies that return a nil context,
+//   - models that are, or have fields that are, pointers,
+//   - models returned by strategies, or plans, that are discarded inside a strategy,
+//   - errors created with a nil error and an empty message,
+//   - strategies after ThrowError with a non-nil error in a Group or Plan, which are never executed.
+var Analyzer = &analysis.Analyzer{
+	Name:     "speedrail",
+	Doc:      "report common misuse of speedrail strategies, models and errors",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// errorConstructors are the functions that create an error, with the index of their outgoing message argument.
+var errorConstructors = map[string]int{
+	"NewError":     2,
+	"Validation":   1,
+	"NotFound":     1,
+	"Conflict":     1,
+	"Unauthorized": 1,
+	"Transient":    1,
+	"Internal":     1,
+}
+
+// run executes the analyzer on a package.
+func run(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	reportedModels := map[string]bool{}
+
+	nodes := []ast.Node{
+		(*ast.FuncDecl)(nil),
+		(*ast.FuncLit)(nil),
+		(*ast.ReturnStmt)(nil),
+		(*ast.AssignStmt)(nil),
+		(*ast.ExprStmt)(nil),
+		(*ast.CallExpr)(nil),
+	}
+
+	inspect.WithStack(nodes, func(node ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+
+		switch node := node.(type) {
+		case *ast.FuncDecl:
+			checkModel(pass, node.Name, node, reportedModels)
+		case *ast.FuncLit:
+			checkModel(pass, node, node, reportedModels)
+		case *ast.ReturnStmt:
+			if strategyModel(pass, enclosingFunc(stack)) != nil {
+				checkReturn(pass, node)
+			}
+		case *ast.AssignStmt:
+			if strategyModel(pass, enclosingFunc(stack)) != nil && len(node.Rhs) == 1 && len(node.Lhs) == 3 {
+				if isBlank(node.Lhs[1]) && returnsModel(pass, node.Rhs[0]) {
+					pass.Reportf(node.Lhs[1].Pos(), "model returned by %s is discarded, changes made to it are lost", callName(node.Rhs[0]))
+				}
+			}
+		case *ast.ExprStmt:
+			if strategyModel(pass, enclosingFunc(stack)) != nil && returnsModel(pass, node.X) {
+				pass.Reportf(node.Pos(), "model returned by %s is discarded, changes made to it are lost", callName(node.X))
+			}
+		case *ast.CallExpr:
+			checkCall(pass, node)
+		}
+
+		return true
+	})
+
+	return nil, nil
+}
+
+// checkModel reports a strategy with a model that is, or has fields that are, pointers. Every model is reported once.
+func checkModel(pass *analysis.Pass, at ast.Node, fn ast.Node, reported map[string]bool) {
+	model := strategyModel(pass, fn)
+	if model == nil {
+		return
+	}
+
+	name := types.TypeString(model, types.RelativeTo(pass.Pkg))
+	if reported[name] {
+		return
+	}
+
+	reported[name] = true
+	if _, ok := model.Underlying().(*types.Pointer); ok {
+		pass.Reportf(at.Pos(), "model %s is a pointer, strategies should receive a copy of the model", name)
+		return
+	}
+
+	if field := pointerField(model, "", map[types.Type]bool{}); field != "" {
+		pass.Reportf(at.Pos(), "model %s has pointer field %s, copies of the model share what it points to", name, field)
+	}
+}
+
+// pointerField returns the path of the first pointer field of a struct, including the fields of nested structs.
+func pointerField(typ types.Type, path string, visited map[types.Type]bool) string {
+	structType, ok := typ.Underlying().(*types.Struct)
+	if !ok || visited[typ] {
+		return ""
+	}
+
+	visited[typ] = true
+	for index := 0; index < structType.NumFields(); index++ {
+		field := structType.Field(index)
+		fieldPath := field.Name()
+		if path != "" {
+			fieldPath = path + "." + fieldPath
+		}
+
+		if _, ok := field.Type().Underlying().(*types.Pointer); ok {
+			return fieldPath
+		}
+
+		if nested := pointerField(field.Type(), fieldPath, visited); nested != "" {
+			return nested
+		}
+	}
+
+	return ""
+}
+
+// checkReturn reports a strategy that returns a nil context.
+func checkReturn(pass *analysis.Pass, node *ast.ReturnStmt) {
+	if len(node.Results) == 3 && isNil(pass, node.Results[0]) {
+		pass.Reportf(node.Results[0].Pos(), "strategy returns a nil context, return the context it was given instead")
+	}
+}
+
+// checkCall reports errors created with a nil error and an empty message, and strategies after ThrowError in a Group or
+// Plan.
+func checkCall(pass *analysis.Pass, call *ast.CallExpr) {
+	name := speedrailFunc(pass, call.Fun)
+	if index, ok := errorConstructors[name]; ok && len(call.Args) > index {
+		if isNil(pass, call.Args[0]) && isEmptyString(pass, call.Args[index]) {
+			pass.Reportf(call.Pos(), "%s with a nil error and an empty message, the error has nothing to report", name)
+		}
+
+		return
+	}
+
+	if (name != "Group" && name != "Plan") || call.Ellipsis.IsValid() {
+		return
+	}
+
+	for index, arg := range call.Args {
+		argCall, ok := astutil.Unparen(arg).(*ast.CallExpr)
+		// ThrowError with a nil error does not stop the execution.
+		if ok && index < len(call.Args)-1 && speedrailFunc(pass, argCall.Fun) == "ThrowError" && len(argCall.Args) == 1 && !isNil(pass, argCall.Args[0]) {
+			pass.Reportf(call.Args[index+1].Pos(), "strategy after ThrowError in %s is never executed", name)
+			return
+		}
+	}
+}
+
+// enclosingFunc returns the innermost function declaration or literal in stack, excluding the last node.
+func enclosingFunc(stack []ast.Node) ast.Node {
+	for index := len(stack) - 2; index >= 0; index-- {
+		switch node := stack[index].(type) {
+		case *ast.FuncDecl, *ast.FuncLit:
+			return node
+		}
+	}
+
+	return nil
+}
+
+// strategyModel returns the model of a function declaration or literal with the signature of a strategy, or nil if it is
+// not a strategy.
+func strategyModel(pass *analysis.Pass, fn ast.Node) types.Type {
+	var signature *types.Signature
+	switch fn := fn.(type) {
+	case *ast.FuncDecl:
+		if object := pass.TypesInfo.Defs[fn.Name]; object != nil {
+			signature, _ = object.Type().(*types.Signature)
+		}
+	case *ast.FuncLit:
+		signature, _ = pass.TypesInfo.TypeOf(fn).(*types.Signature)
+	}
+
+	if signature == nil || signature.Params().Len() != 3 || !isContext(signature.Params().At(0).Type()) {
+		return nil
+	}
+
+	model := resultModel(signature.Results())
+	if model == nil || !types.Identical(model, signature.Params().At(2).Type()) {
+		return nil
+	}
+
+	return model
+}
+
+// returnsModel reports if expr is a call that returns a context, a model and an error, like a strategy or Execute.
+func returnsModel(pass *analysis.Pass, expr ast.Expr) bool {
+	if _, ok := astutil.Unparen(expr).(*ast.CallExpr); !ok {
+		return false
+	}
+
+	results, ok := pass.TypesInfo.TypeOf(expr).(*types.Tuple)
+	return ok && resultModel(results) != nil
+}
+
+// resultModel returns the model of the results of a strategy, or nil if results are not a context, a model and an error.
+func resultModel(results *types.Tuple) types.Type {
+	if results.Len() != 3 || !isContext(results.At(0).Type()) || !isSpeedrailType(results.At(2).Type(), "Error") {
+		return nil
+	}
+
+	return results.At(1).Type()
+}
+
+// speedrailFunc returns the name of the speedrail function that expr refers to, or an empty string.
+func speedrailFunc(pass *analysis.Pass, expr ast.Expr) string {
+	switch fun := astutil.Unparen(expr).(type) {
+	case *ast.IndexExpr:
+		expr = fun.X
+	case *ast.IndexListExpr:
+		expr = fun.X
+	}
+
+	var ident *ast.Ident
+	switch fun := astutil.Unparen(expr).(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return ""
+	}
+
+	fn, ok := pass.TypesInfo.Uses[ident].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != speedrailPath || fn.Type().(*types.Signature).Recv() != nil {
+		return ""
+	}
+
+	return fn.Name()
+}
+
+// callName returns the name of the function that is called by expr, used in diagnostics.
+func callName(expr ast.Expr) string {
+	call, ok := astutil.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return "the call"
+	}
+
+	switch fun := astutil.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name
+	}
+
+	return "the call"
+}
+
+// isContext reports if typ is context.Context.
+func isContext(typ types.Type) bool {
+	named, ok := typ.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "context" && named.Obj().Name() == "Context"
+}
+
+// isSpeedrailType reports if typ is the named type of the speedrail package.
+func isSpeedrailType(typ types.Type, name string) bool {
+	named, ok := typ.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == speedrailPath && named.Obj().Name() == name
+}
+
+// isNil reports if expr is the nil value.
+func isNil(pass *analysis.Pass, expr ast.Expr) bool {
+	return pass.TypesInfo.Types[expr].IsNil()
+}
+
+// isEmptyString reports if expr is a constant empty string.
+func isEmptyString(pass *analysis.Pass, expr ast.Expr) bool {
+	value := pass.TypesInfo.Types[expr].Value
+	return value != nil && value.Kind() == constant.String && strings.TrimSpace(constant.StringVal(value)) == ""
+}
+
+// isBlank reports if expr is the blank identifier.
+func isBlank(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "_"
+}
@@ -0,0 +1,20 @@
+package lint_test
+
+import (
+	"github.com/Kansuler/speedrail/cmd/speedrail-lint/lint"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"testing"
+)
+
+type LintTestSuite struct {
+	suite.Suite
+}
+
+func (suite *LintTestSuite) TestAnalyzer() {
+	analysistest.Run(suite.T(), analysistest.TestData(), lint.Analyzer, "a")
+}
+
+func TestLintTestSuite(t *testing.T) {
+	suite.Run(t, new(LintTestSuite))
+}
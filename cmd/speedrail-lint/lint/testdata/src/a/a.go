@@ -0,0 +1,77 @@
+package a
+
+import (
+	"context"
+	"errors"
+	"github.com/Kansuler/speedrail"
+)
+
+type Address struct {
+	Street string
+	Owner  *User
+}
+
+type User struct {
+	Name    string
+	Address Address
+}
+
+type Order struct {
+	ID    string
+	Items []string
+}
+
+func SetName(ctx context.Context, container any, model User) (context.Context, User, speedrail.Error) { // want `model User has pointer field Address.Owner, copies of the model share what it points to`
+	model.Name = "name"
+	return ctx, model, nil
+}
+
+func Missing(ctx context.Context, container any, model Order) (context.Context, Order, speedrail.Error) {
+	if model.ID == "" {
+		return nil, model, speedrail.NewError(nil, 400, "") // want `strategy returns a nil context, return the context it was given instead` `NewError with a nil error and an empty message, the error has nothing to report`
+	}
+
+	return ctx, model, speedrail.Validation(errors.New("invalid"), "")
+}
+
+func Pointer(ctx context.Context, container any, model *Order) (context.Context, *Order, speedrail.Error) { // want `model \*Order is a pointer, strategies should receive a copy of the model`
+	return ctx, model, nil
+}
+
+func Discard(ctx context.Context, container any, model Order) (context.Context, Order, speedrail.Error) {
+	ctx, _, err := Missing(ctx, container, model) // want `model returned by Missing is discarded, changes made to it are lost`
+	if err != nil {
+		return ctx, model, err
+	}
+
+	speedrail.Plan(Missing).Execute(ctx, container, model) // want `model returned by Execute is discarded, changes made to it are lost`
+
+	inner := func(ctx context.Context, container any, model Order) (context.Context, Order, speedrail.Error) {
+		return nil, model, speedrail.Validation(nil, " ") // want `strategy returns a nil context, return the context it was given instead` `Validation with a nil error and an empty message, the error has nothing to report`
+	}
+
+	return inner(ctx, container, model)
+}
+
+func Plans() {
+	failed := speedrail.NewError(errors.New("failed"), 500, "failed")
+	throw := speedrail.ThrowError[any, Order](failed)
+
+	speedrail.Plan(
+		Missing,
+		speedrail.Group(Missing, speedrail.ThrowError[any, Order](failed), Discard), // want `strategy after ThrowError in Group is never executed`
+		speedrail.Group(Missing, speedrail.ThrowError[any, Order](nil), Discard),
+		speedrail.Merge(speedrail.ThrowError[any, Order](nil), Discard),
+		throw,
+		Discard,
+	)
+
+	speedrail.Plan(speedrail.ThrowError[any, Order](failed), Missing) // want `strategy after ThrowError in Plan is never executed`
+	speedrail.Plan(speedrail.ThrowError[any, Order](nil), Missing)
+
+	_, _, _ = speedrail.Plan(Missing, Discard).Execute(context.Background(), nil, Order{})
+}
+
+func Helper(ctx context.Context, model Order) (context.Context, Order) {
+	return nil, model
+}
@@ -0,0 +1,43 @@
+// Package speedrail is a stub of the speedrail package, with the declarations that the analyzer looks for.
+package speedrail
+
+import "context"
+
+type Error interface {
+	error
+	StatusCode() int
+}
+
+type ErrorOption func()
+
+type Strategy[C, M any] func(context.Context, C, M) (context.Context, M, Error)
+
+type Speedrail[C, M any] []Strategy[C, M]
+
+func (s Speedrail[C, M]) Execute(ctx context.Context, container C, model M) (context.Context, M, Error) {
+	return ctx, model, nil
+}
+
+func Plan[C, M any](strategies ...Strategy[C, M]) Speedrail[C, M] {
+	return strategies
+}
+
+func Group[C, M any](strategies ...Strategy[C, M]) Strategy[C, M] {
+	return nil
+}
+
+func Merge[C, M any](strategies ...Strategy[C, M]) Strategy[C, M] {
+	return nil
+}
+
+func ThrowError[C, M any](err Error) Strategy[C, M] {
+	return nil
+}
+
+func NewError(err error, statusCode int, outgoingMessage string, options ...ErrorOption) Error {
+	return nil
+}
+
+func Validation(err error, outgoingMessage string, options ...ErrorOption) Error {
+	return nil
+}
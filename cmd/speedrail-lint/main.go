@@ -0,0 +1,15 @@
+// Command speedrail-lint reports common misuse of speedrail, see the lint package. It can be run on its own, or with go
+// vet:
+//
+//	go install github.com/Kansuler/speedrail/cmd/speedrail-lint@latest
+//	go vet -vettool=$(which speedrail-lint) ./...
+package main
+
+import (
+	"github.com/Kansuler/speedrail/cmd/speedrail-lint/lint"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(lint.Analyzer)
+}
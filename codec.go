@@ -0,0 +1,27 @@
+package speedrail
+
+import "encoding/json"
+
+// Codec serializes models, so that they can be persisted.
+type Codec[M any] interface {
+	Marshal(M) ([]byte, error)
+	Unmarshal([]byte) (M, error)
+}
+
+// JSONCodec is a Codec that serializes models as JSON.
+type JSONCodec[M any] struct{}
+
+// Type check that JSONCodec implements Codec interface
+var _ Codec[any] = JSONCodec[any]{}
+
+// Marshal returns the JSON encoding of model.
+func (JSONCodec[M]) Marshal(model M) ([]byte, error) {
+	return json.Marshal(model)
+}
+
+// Unmarshal parses the JSON encoded data into a model.
+func (JSONCodec[M]) Unmarshal(data []byte) (M, error) {
+	var model M
+	err := json.Unmarshal(data, &model)
+	return model, err
+}
@@ -5,6 +5,10 @@ type Condition[M any] func(M) bool
 
 // And receives conditions, if all of them are true condition is passed.
 func And[M any](conditions ...Condition[M]) Condition[M] {
+	if hasInvalidCondition(conditions) {
+		return invalidCondition[M]()
+	}
+
 	return func(model M) bool {
 		for _, condition := range conditions {
 			if !condition(model) {
@@ -18,6 +22,10 @@ func And[M any](conditions ...Condition[M]) Condition[M] {
 
 // Or receives conditions, if one of them is true condition is passed.
 func Or[M any](conditions ...Condition[M]) Condition[M] {
+	if hasInvalidCondition(conditions) {
+		return invalidCondition[M]()
+	}
+
 	return func(model M) bool {
 		for _, condition := range conditions {
 			if condition(model) {
@@ -31,7 +39,22 @@ func Or[M any](conditions ...Condition[M]) Condition[M] {
 
 // Not will invert a condition
 func Not[M any](condition Condition[M]) Condition[M] {
+	if hasInvalidCondition([]Condition[M]{condition}) {
+		return invalidCondition[M]()
+	}
+
 	return func(model M) bool {
 		return !condition(model)
 	}
 }
+
+// hasInvalidCondition reports if any of the conditions is nil, or was itself given a nil condition.
+func hasInvalidCondition[M any](conditions []Condition[M]) bool {
+	for _, condition := range conditions {
+		if condition == nil || isInvalidCondition(condition) {
+			return true
+		}
+	}
+
+	return false
+}
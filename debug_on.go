@@ -0,0 +1,7 @@
+//go:build speedrail_debug
+
+package speedrail
+
+// Debug is true when built with the speedrail_debug build tag. Internal details of errors, such as the trail, are only
+// rendered by default in debug builds.
+const Debug = true
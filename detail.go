@@ -0,0 +1,100 @@
+package speedrail
+
+// ErrorOption attaches machine-readable data to an error created with NewError.
+type ErrorOption func(*ErrorWithTrail)
+
+// WithCode sets an application error code, such as USER_EXISTS, on the error.
+func WithCode(code string) ErrorOption {
+	return func(entry *ErrorWithTrail) {
+		entry.Code = code
+	}
+}
+
+// WithField sets the path of the field in the model that the error concerns, such as email.
+func WithField(field string) ErrorOption {
+	return func(entry *ErrorWithTrail) {
+		entry.Field = field
+	}
+}
+
+// WithMeta adds a key and value to the metadata of the error.
+func WithMeta(key string, value any) ErrorOption {
+	return func(entry *ErrorWithTrail) {
+		if entry.Metadata == nil {
+			entry.Metadata = map[string]any{}
+		}
+
+		entry.Metadata[key] = value
+	}
+}
+
+// WithMessageKey sets the key of the outgoing message in a catalog of translations, and the arguments of its template,
+// so that the message can be translated with Localize. The outgoing message given to NewError is used when there is no
+// translation.
+func WithMessageKey(key string, args map[string]any) ErrorOption {
+	return func(entry *ErrorWithTrail) {
+		entry.MessageKey = key
+		entry.MessageArgs = args
+	}
+}
+
+// Detail is the machine-readable data of an entry in the trail of an error, that is safe to return to a client.
+type Detail struct {
+	Field    string         `json:"field,omitempty"`
+	Code     string         `json:"code,omitempty"`
+	Message  string         `json:"message"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// Details returns the details of every entry in the trail of err that has a code, field or metadata, in order.
+func Details(err Error) []Detail {
+	var details []Detail
+	for _, entry := range err.Trail() {
+		if entry.Code == "" && entry.Field == "" && len(entry.Metadata) == 0 {
+			continue
+		}
+
+		details = append(details, Detail{
+			Field:    entry.Field,
+			Code:     entry.Code,
+			Message:  entry.Message,
+			Metadata: entry.Metadata,
+		})
+	}
+
+	return details
+}
+
+// ErrorCode returns the first application error code in the trail of err, or an empty string if there is none.
+func ErrorCode(err Error) string {
+	for _, entry := range err.Trail() {
+		if entry.Code != "" {
+			return entry.Code
+		}
+	}
+
+	return ""
+}
+
+// FieldErrors returns the outgoing messages of err grouped by field, for entries in the trail that concern a field.
+func FieldErrors(err Error) map[string][]string {
+	fields := map[string][]string{}
+	for _, entry := range err.Trail() {
+		if entry.Field != "" {
+			fields[entry.Field] = append(fields[entry.Field], entry.Message)
+		}
+	}
+
+	return fields
+}
+
+// Meta returns the first value stored for key in the metadata of the trail of err.
+func Meta(err Error, key string) (any, bool) {
+	for _, entry := range err.Trail() {
+		if value, ok := entry.Metadata[key]; ok {
+			return value, true
+		}
+	}
+
+	return nil, false
+}
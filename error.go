@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -24,49 +27,215 @@ type defaultError struct {
 	statusCode      int
 }
 
-// Type check that default error implements Error interface
-var _ Error = defaultError{}
+// Type check that default error implements Error, PolicyMerger, TrailMapper and Localizer interface
+var (
+	_ Error        = defaultError{}
+	_ PolicyMerger = defaultError{}
+	_ TrailMapper  = defaultError{}
+	_ Localizer    = defaultError{}
+)
+
+// errorJSON is the JSON encoding of an error.
+type errorJSON struct {
+	Error   string   `json:"error"`
+	Code    string   `json:"code,omitempty"`
+	Details []Detail `json:"details,omitempty"`
+}
 
+// MarshalJSON encodes the outgoing message of the error, together with the code and details of the trail if there are
+// any.
 func (e defaultError) MarshalJSON() ([]byte, error) {
-	return json.Marshal(map[string]string{"error": e.Error()})
+	return json.Marshal(errorJSON{Error: e.Error(), Code: ErrorCode(e), Details: Details(e)})
 }
 
+// ErrorWithTrail is an entry in the trail of an error.
 type ErrorWithTrail struct {
 	StrategyName string
 	Error        error
+	StatusCode   int
+	// Message is the outgoing message of the error the entry was created with.
+	Message string
+	// Code is a machine-readable application error code, such as USER_EXISTS.
+	Code string
+	// Field is the path of the field in the model that the error concerns, such as email.
+	Field    string
+	Metadata map[string]any
+	// Category is the class of the error, such as CategoryNotFound.
+	Category Category
+	// MessageKey is the key of the outgoing message in a catalog of translations, and MessageArgs the arguments of its
+	// template. Message is used when there is no translation.
+	MessageKey  string
+	MessageArgs map[string]any
+	// Stack is the stack where the error was created, it is only captured when enabled with CaptureStacks or WithStack.
+	Stack []Frame
 }
 
-// Trail is a map of errors, with a custom marshaler.
+// Trail is a list of errors, with a custom marshaler.
 type Trail []ErrorWithTrail
 
+// TrailVersion is the version of the JSON encoding of Trail.
+const TrailVersion = 1
+
+// trailJSON is the JSON encoding of Trail.
+type trailJSON struct {
+	Version int              `json:"version"`
+	Entries []trailEntryJSON `json:"entries"`
+}
+
+// trailEntryJSON is the JSON encoding of an entry in Trail.
+type trailEntryJSON struct {
+	Strategy        string         `json:"strategy"`
+	Message         string         `json:"message"`
+	StatusCode      int            `json:"status_code,omitempty"`
+	OutgoingMessage string         `json:"outgoing_message,omitempty"`
+	Code            string         `json:"code,omitempty"`
+	Field           string         `json:"field,omitempty"`
+	Metadata        map[string]any `json:"metadata,omitempty"`
+	Category        Category       `json:"category,omitempty"`
+	MessageKey      string         `json:"message_key,omitempty"`
+	MessageArgs     map[string]any `json:"message_args,omitempty"`
+	Stack           []Frame        `json:"stack,omitempty"`
+}
+
+// MarshalJSON encodes the trail as a versioned object, with the entries in order.
 func (t Trail) MarshalJSON() ([]byte, error) {
-	result := map[string]string{}
-	for index, err := range t {
-		if strings.Contains(err.StrategyName, "/") && strings.LastIndex(err.StrategyName, "/") < len(err.StrategyName) {
-			result[fmt.Sprintf("[%d]%s", index+1, err.StrategyName[strings.LastIndex(err.StrategyName, "/")+1:])] = err.Error.Error()
-			continue
+	result := trailJSON{Version: TrailVersion, Entries: make([]trailEntryJSON, 0, len(t))}
+	for _, err := range t {
+		entry := trailEntryJSON{
+			Strategy:        err.StrategyName,
+			StatusCode:      err.StatusCode,
+			OutgoingMessage: err.Message,
+			Code:            err.Code,
+			Field:           err.Field,
+			Metadata:        err.Metadata,
+			Category:        err.Category,
+			MessageKey:      err.MessageKey,
+			MessageArgs:     err.MessageArgs,
+			Stack:           err.Stack,
 		}
 
-		result[fmt.Sprintf("[%d]%s", index+1, err.StrategyName)] = err.Error.Error()
+		if err.Error != nil {
+			entry.Message = err.Error.Error()
+		}
 
+		result.Entries = append(result.Entries, entry)
 	}
 
 	return json.Marshal(result)
 }
 
+// UnmarshalJSON decodes a trail encoded by MarshalJSON. The errors of the decoded trail only hold the message of the
+// original errors. The unversioned encoding of earlier releases, keyed like [1]pkg.func, is decoded as well.
+func (t *Trail) UnmarshalJSON(data []byte) error {
+	var versioned struct {
+		Version int             `json:"version"`
+		Entries json.RawMessage `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &versioned); err != nil {
+		return err
+	}
+
+	switch versioned.Version {
+	case 0:
+		return t.unmarshalLegacyJSON(data)
+	case TrailVersion:
+	default:
+		return fmt.Errorf("unsupported trail version %d", versioned.Version)
+	}
+
+	var entries []trailEntryJSON
+	if err := json.Unmarshal(versioned.Entries, &entries); err != nil {
+		return err
+	}
+
+	trail := make(Trail, 0, len(entries))
+	for _, entry := range entries {
+		trail = append(trail, ErrorWithTrail{
+			StrategyName: entry.Strategy,
+			Error:        errors.New(entry.Message),
+			StatusCode:   entry.StatusCode,
+			Message:      entry.OutgoingMessage,
+			Code:         entry.Code,
+			Field:        entry.Field,
+			Metadata:     entry.Metadata,
+			Category:     entry.Category,
+			MessageKey:   entry.MessageKey,
+			MessageArgs:  entry.MessageArgs,
+			Stack:        entry.Stack,
+		})
+	}
+
+	*t = trail
+	return nil
+}
+
+// unmarshalLegacyJSON decodes the unversioned encoding, a map of messages keyed by position and strategy name.
+func (t *Trail) unmarshalLegacyJSON(data []byte) error {
+	var legacy map[string]string
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+
+	type positioned struct {
+		position int
+		entry    ErrorWithTrail
+	}
+
+	entries := make([]positioned, 0, len(legacy))
+	for key, message := range legacy {
+		end := strings.Index(key, "]")
+		if !strings.HasPrefix(key, "[") || end < 0 {
+			return fmt.Errorf("invalid trail key %q", key)
+		}
+
+		position, err := strconv.Atoi(key[1:end])
+		if err != nil {
+			return fmt.Errorf("invalid trail key %q: %w", key, err)
+		}
+
+		entries = append(entries, positioned{
+			position: position,
+			entry:    ErrorWithTrail{StrategyName: key[end+1:], Error: errors.New(message)},
+		})
+	}
+
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].position < entries[b].position
+	})
+
+	trail := make(Trail, 0, len(entries))
+	for _, entry := range entries {
+		trail = append(trail, entry.entry)
+	}
+
+	*t = trail
+	return nil
+}
+
 // Trail returns the error trail
 func (e defaultError) Trail() Trail {
 	return e.trail
 }
 
-// Merge will merge two errors together.
+// Merge will merge two errors together with DefaultMergePolicy.
 func (e defaultError) Merge(err Error) Error {
-	e.trail = append(e.trail, err.Trail()...)
-	if e.StatusCode() < err.StatusCode() {
-		e.statusCode = err.StatusCode()
+	return e.MergeWithPolicy(err, DefaultMergePolicy)
+}
+
+// MergeWithPolicy will merge two errors together, with the status code and outgoing message decided by policy.
+func (e defaultError) MergeWithPolicy(err Error, policy MergePolicy) Error {
+	if policy.StatusCode == nil {
+		policy.StatusCode = DefaultMergePolicy.StatusCode
 	}
 
-	e.outgoingMessage = strings.Join([]string{e.outgoingMessage, err.Error()}, "; ")
+	if policy.Message == nil {
+		policy.Message = DefaultMergePolicy.Message
+	}
+
+	trail := make(Trail, 0, len(e.trail)+len(err.Trail()))
+	e.trail = append(append(trail, e.trail...), err.Trail()...)
+	e.statusCode = policy.StatusCode(e.statusCode, err.StatusCode())
+	e.outgoingMessage = policy.Message(e.outgoingMessage, err.Error())
 	return e
 }
 
@@ -80,58 +249,117 @@ func (e defaultError) Error() string {
 	return e.outgoingMessage
 }
 
-// Unwrap will return the underlying error.
-func (e defaultError) Unwrap() error {
+// Unwrap will return the errors of every entry in the trail, so that errors.Is and errors.As inspect all of them.
+func (e defaultError) Unwrap() []error {
+	errs := make([]error, 0, len(e.trail))
 	for _, err := range e.trail {
-		return err.Error
+		if err.Error != nil {
+			errs = append(errs, err.Error)
+		}
 	}
 
-	return nil
+	return errs
 }
 
-// Is checks if the error is of the given type.
-func (e defaultError) Is(target error) bool {
-	for _, err := range e.trail {
-		if errors.Is(err.Error, target) {
-			return true
-		}
+// TrailMapper is implemented by errors that can rewrite the entries of their trail, and keep the rest of the error, such
+// as errors that wrap another error.
+type TrailMapper interface {
+	MapTrail(func(ErrorWithTrail) ErrorWithTrail) Error
+}
+
+// MapTrail rewrites the entries of the trail of err with fn, and keeps the rest of the error, such as the status code and
+// outgoing message. Errors that do not implement TrailMapper are rebuilt with ErrorFromTrail.
+func MapTrail(err Error, fn func(ErrorWithTrail) ErrorWithTrail) Error {
+	if mapper, ok := err.(TrailMapper); ok {
+		return mapper.MapTrail(fn)
 	}
 
-	return false
+	return ErrorFromTrail(mapEntries(err.Trail(), fn), err.StatusCode(), err.Error())
 }
 
-// As checks if the error can be cast as this type.
-func (e defaultError) As(target any) bool {
-	for _, err := range e.trail {
-		if errors.As(err.Error, target) {
-			return true
-		}
+// MapTrail rewrites the entries of the trail with fn.
+func (e defaultError) MapTrail(fn func(ErrorWithTrail) ErrorWithTrail) Error {
+	e.trail = mapEntries(e.trail, fn)
+	return e
+}
+
+// mapEntries returns a copy of trail with the entries rewritten by fn.
+func mapEntries(trail Trail, fn func(ErrorWithTrail) ErrorWithTrail) Trail {
+	mapped := make(Trail, 0, len(trail))
+	for _, entry := range trail {
+		mapped = append(mapped, fn(entry))
+	}
+
+	return mapped
+}
+
+// ErrorFromTrail will return an error with a trail that was decoded from another service, so that errors can cross
+// service boundaries.
+func ErrorFromTrail(trail Trail, statusCode int, outgoingMessage string) Error {
+	return defaultError{
+		trail:           trail,
+		statusCode:      statusCode,
+		outgoingMessage: outgoingMessage,
+	}
+}
+
+// NewError will return a default error struct. Options may be given to attach a code, field and metadata to the error.
+func NewError(err error, statusCode int, outgoingMessage string, options ...ErrorOption) Error {
+	return newError(callerName(2), captureStack(2), err, statusCode, outgoingMessage, options...)
+}
+
+// callerName returns the name of the function skip frames up the stack, where 1 is the caller of callerName.
+func callerName(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip)
+	details := runtime.FuncForPC(pc)
+	if ok && details != nil {
+		return details.Name()
 	}
 
-	return false
+	return "unknown"
 }
 
-// NewError will return a default error struct.
-func NewError(err error, statusCode int, outgoingMessage string) Error {
+// newError will return a default error struct, with the given strategy name and stack in the trail.
+func newError(strategyName string, stack []Frame, err error, statusCode int, outgoingMessage string, options ...ErrorOption) Error {
 	// Create err if it is nil.
 	if err == nil {
 		err = errors.New(outgoingMessage)
 	}
 
-	strategyName := "unknown"
-	pc, _, _, ok := runtime.Caller(1)
-	details := runtime.FuncForPC(pc)
-	if ok && details != nil {
-		strategyName = details.Name()
+	entry := ErrorWithTrail{
+		StrategyName: strategyName,
+		Error:        err,
+		StatusCode:   statusCode,
+		Message:      outgoingMessage,
+		Stack:        stack,
 	}
+	for _, option := range options {
+		option(&entry)
+	}
+
 	return defaultError{
-		trail: []ErrorWithTrail{
-			{
-				StrategyName: strategyName,
-				Error:        err,
-			},
-		},
+		trail:           []ErrorWithTrail{entry},
 		statusCode:      statusCode,
 		outgoingMessage: outgoingMessage,
 	}
 }
+
+// FromError will wrap any error into a speedrail error, like Wrap without options. If err already is a speedrail error
+// it is returned as is, and errors joined with errors.Join are merged into one speedrail error with an entry in the trail
+// each. The status code and outgoing message are picked from DefaultErrorRegistry, so that internal errors are not
+// exposed.
+func FromError(err error) Error {
+	return DefaultErrorRegistry.wrap(callerName(2), captureStack(2), err, nil)
+}
+
+// StatusClientClosedRequest is the non-standard status code used when the client cancelled the request.
+const StatusClientClosedRequest = 499
+
+// statusText returns the text of a status code, including non-standard status codes used by speedrail.
+func statusText(statusCode int) string {
+	if statusCode == StatusClientClosedRequest {
+		return "Client Closed Request"
+	}
+
+	return http.StatusText(statusCode)
+}
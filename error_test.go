@@ -1,10 +1,13 @@
 package speedrail_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/Kansuler/speedrail"
 	"github.com/stretchr/testify/suite"
+	"io/fs"
 	"net/http"
 	"testing"
 )
@@ -41,12 +44,133 @@ func (suite *SpeedrailErrorTestSuite) TestMerge() {
 	suite.False(errors.Is(result, error3))
 	b, err := json.Marshal(result.Trail())
 	suite.NoError(err)
-	suite.JSONEq(`{"[1]speedrail_test.errorTestFunction1":"error 1","[2]speedrail_test.errorTestFunction2":"error 2","[3]speedrail_test.errorTestFunction2":"error 2"}`, string(b))
+	suite.JSONEq(`{"version":1,"entries":[
+		{"strategy":"github.com/Kansuler/speedrail_test.errorTestFunction1","message":"error 1","status_code":500,"outgoing_message":"error 1 message"},
+		{"strategy":"github.com/Kansuler/speedrail_test.errorTestFunction2","message":"error 2","status_code":500,"outgoing_message":"error 2 message"},
+		{"strategy":"github.com/Kansuler/speedrail_test.errorTestFunction2","message":"error 2","status_code":500,"outgoing_message":"error 2 message"}
+	]}`, string(b))
 
 	err4 := speedrail.NewError(nil, http.StatusInternalServerError, "error 3 message")
 	b, err = json.Marshal(err4.Trail())
 	suite.NoError(err)
-	suite.JSONEq(`{"[1]speedrail_test.(*SpeedrailErrorTestSuite).TestMerge":"error 3 message"}`, string(b))
+	suite.JSONEq(`{"version":1,"entries":[{"strategy":"github.com/Kansuler/speedrail_test.(*SpeedrailErrorTestSuite).TestMerge","message":"error 3 message","status_code":500,"outgoing_message":"error 3 message"}]}`, string(b))
+}
+
+func (suite *SpeedrailErrorTestSuite) TestTrailRoundTrip() {
+	var result speedrail.Error = errorTestFunction1()
+	for i := 0; i < 10; i++ {
+		result = result.Merge(speedrail.NewError(fmt.Errorf("error %d", i+2), http.StatusBadRequest, "message"))
+	}
+
+	b, err := json.Marshal(result.Trail())
+	suite.NoError(err)
+
+	var trail speedrail.Trail
+	suite.NoError(json.Unmarshal(b, &trail))
+	suite.Equal(11, len(trail))
+	for i, entry := range trail {
+		suite.Equal(result.Trail()[i].StrategyName, entry.StrategyName)
+		suite.Equal(result.Trail()[i].StatusCode, entry.StatusCode)
+		suite.Equal(result.Trail()[i].Error.Error(), entry.Error.Error())
+	}
+
+	decoded := speedrail.ErrorFromTrail(trail, result.StatusCode(), result.Error())
+	suite.Equal(result.StatusCode(), decoded.StatusCode())
+	suite.Equal(result.Error(), decoded.Error())
+	suite.Equal(11, len(decoded.Trail()))
+
+	suite.Error(json.Unmarshal([]byte(`{"version":2,"entries":[]}`), &trail))
+}
+
+func (suite *SpeedrailErrorTestSuite) TestTrailLegacyJSON() {
+	var trail speedrail.Trail
+	suite.NoError(json.Unmarshal([]byte(`{"[10]pkg.ten":"error 10","[2]pkg.two":"error 2","[1]pkg.one":"error 1"}`), &trail))
+	suite.Equal(3, len(trail))
+	suite.Equal("pkg.one", trail[0].StrategyName)
+	suite.Equal("pkg.two", trail[1].StrategyName)
+	suite.Equal("pkg.ten", trail[2].StrategyName)
+	suite.Equal("error 10", trail[2].Error.Error())
+}
+
+func (suite *SpeedrailErrorTestSuite) TestDetails() {
+	result := speedrail.NewError(errors.New("duplicate key"), http.StatusConflict, "user already exists", speedrail.WithCode("USER_EXISTS"), speedrail.WithField("email")).
+		Merge(speedrail.NewError(nil, http.StatusBadRequest, "password too short", speedrail.WithCode("INVALID_PASSWORD"), speedrail.WithField("password"), speedrail.WithMeta("min_length", 8))).
+		Merge(speedrail.NewError(nil, http.StatusBadRequest, "password is missing a digit", speedrail.WithField("password"))).
+		Merge(speedrail.NewError(nil, http.StatusBadRequest, "no details"))
+
+	suite.Equal("USER_EXISTS", speedrail.ErrorCode(result))
+	suite.Equal(map[string][]string{
+		"email":    {"user already exists"},
+		"password": {"password too short", "password is missing a digit"},
+	}, speedrail.FieldErrors(result))
+
+	value, ok := speedrail.Meta(result, "min_length")
+	suite.True(ok)
+	suite.Equal(8, value)
+	_, ok = speedrail.Meta(result, "max_length")
+	suite.False(ok)
+
+	b, err := json.Marshal(result)
+	suite.NoError(err)
+	suite.JSONEq(`{
+		"error":"user already exists; password too short; password is missing a digit; no details",
+		"code":"USER_EXISTS",
+		"details":[
+			{"field":"email","code":"USER_EXISTS","message":"user already exists"},
+			{"field":"password","code":"INVALID_PASSWORD","message":"password too short","metadata":{"min_length":8}},
+			{"field":"password","message":"password is missing a digit"}
+		]
+	}`, string(b))
+
+	b, err = json.Marshal(errorTestFunction1())
+	suite.NoError(err)
+	suite.JSONEq(`{"error":"error 1 message"}`, string(b))
+}
+
+func (suite *SpeedrailErrorTestSuite) TestUnwrap() {
+	result := errorTestFunction1().Merge(errorTestFunction2())
+
+	unwrapper, ok := result.(interface{ Unwrap() []error })
+	suite.True(ok)
+	suite.Equal([]error{error1, error2}, unwrapper.Unwrap())
+
+	var pathErr *fs.PathError
+	withPathErr := result.Merge(speedrail.NewError(&fs.PathError{Op: "open", Path: "/tmp", Err: fs.ErrNotExist}, http.StatusNotFound, "not found"))
+	suite.True(errors.As(withPathErr, &pathErr))
+	suite.True(errors.Is(withPathErr, fs.ErrNotExist))
+
+	joined := errors.Join(result, error3)
+	suite.True(errors.Is(joined, error1))
+	suite.True(errors.Is(joined, error2))
+	suite.True(errors.Is(joined, error3))
+}
+
+func (suite *SpeedrailErrorTestSuite) TestFromError() {
+	suite.Nil(speedrail.FromError(nil))
+
+	original := errorTestFunction1()
+	suite.Equal(original, speedrail.FromError(original))
+
+	err := speedrail.FromError(error3)
+	suite.Equal(http.StatusInternalServerError, err.StatusCode())
+	suite.Equal("Internal Server Error", err.Error())
+	suite.True(errors.Is(err, error3))
+	suite.Equal("github.com/Kansuler/speedrail_test.(*SpeedrailErrorTestSuite).TestFromError", err.Trail()[0].StrategyName)
+
+	err = speedrail.FromError(fmt.Errorf("query user: %w", context.DeadlineExceeded))
+	suite.Equal(http.StatusGatewayTimeout, err.StatusCode())
+
+	err = speedrail.FromError(context.Canceled)
+	suite.Equal(speedrail.StatusClientClosedRequest, err.StatusCode())
+	suite.Equal("Client Closed Request", err.Error())
+
+	err = speedrail.FromError(errors.Join(errorTestFunction2(), fs.ErrNotExist, error3))
+	suite.Equal(3, len(err.Trail()))
+	suite.Equal(http.StatusInternalServerError, err.StatusCode())
+	suite.Equal("error 2 message; Not Found; Internal Server Error", err.Error())
+	suite.True(errors.Is(err, error2))
+	suite.True(errors.Is(err, fs.ErrNotExist))
+	suite.True(errors.Is(err, error3))
 }
 
 func TestSpeedrailErrorTestSuite(t *testing.T) {
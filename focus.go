@@ -0,0 +1,63 @@
+package speedrail
+
+import (
+	"context"
+	"strings"
+)
+
+// Focus will return a strategy that executes a plan over a part of the model, such as the address of a user, so that
+// plans can be reused for models they are embedded in. The part is read from the model with get, and written back with
+// set after the plan is executed, also if it fails. The entries in the trail of errors are prefixed with path, so an
+// error with field street becomes address.street, and its strategy name is prefixed with "address: ". Entries without a
+// field keep their empty field, and errors that wrap another error are kept if they implement TrailMapper.
+func Focus[C, M, N any](path string, get func(M) N, set func(M, N) M, plan Speedrail[C, N]) Strategy[C, M] {
+	problems := append(nilProblem("get", get == nil), nilProblem("set", set == nil)...)
+	problems = append(problems, planProblems("plan", plan)...)
+	return validated("Focus", problems, func(ctx context.Context, container C, model M) (context.Context, M, Error) {
+		ctx, part, err := plan.Execute(ctx, container, get(model))
+		model = set(model, part)
+		if err != nil {
+			return ctx, model, prefixTrail(path, err)
+		}
+
+		return ctx, model, nil
+	})
+}
+
+// prefixTrail will return err with path prefixed to the fields and strategy names of the trail.
+func prefixTrail(path string, err Error) Error {
+	if path == "" {
+		return err
+	}
+
+	return MapTrail(err, func(entry ErrorWithTrail) ErrorWithTrail {
+		if entry.Field != "" {
+			entry.Field = joinFieldPath(path, entry.Field)
+		}
+
+		if entry.StrategyName != "" {
+			entry.StrategyName = path + ": " + entry.StrategyName
+		}
+
+		return entry
+	})
+}
+
+// joinFieldPath joins the path of a field to the path of the model it is in, such as address and street, or items and
+// [0].name.
+func joinFieldPath(path, field string) string {
+	if strings.HasPrefix(field, "[") {
+		return path + field
+	}
+
+	return path + "." + field
+}
+
+// WithContainer will return a strategy that executes a plan with a narrower container, such as an interface that a
+// shared package of strategies is written against. The container of the plan is derived from the container with adapt.
+func WithContainer[C, D, M any](adapt func(C) D, plan Speedrail[D, M]) Strategy[C, M] {
+	problems := append(nilProblem("adapt", adapt == nil), planProblems("plan", plan)...)
+	return validated("WithContainer", problems, func(ctx context.Context, container C, model M) (context.Context, M, Error) {
+		return plan.Execute(ctx, adapt(container), model)
+	})
+}
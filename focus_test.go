@@ -0,0 +1,142 @@
+package speedrail_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Kansuler/speedrail"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type SpeedrailFocusTestSuite struct {
+	suite.Suite
+}
+
+type focusTestAddress struct {
+	Street string
+	City   string
+}
+
+type focusTestUser struct {
+	Name    string
+	Address focusTestAddress
+}
+
+func focusTestAddressPlan() speedrail.Speedrail[any, focusTestAddress] {
+	return speedrail.Plan(
+		func(ctx context.Context, container any, model focusTestAddress) (context.Context, focusTestAddress, speedrail.Error) {
+			model.City = strings.ToUpper(model.City)
+			return ctx, model, nil
+		},
+		speedrail.Merge(
+			speedrail.If(
+				func(model focusTestAddress) bool {
+					return model.Street == ""
+				},
+				speedrail.ThrowError[any, focusTestAddress](speedrail.Validation(errors.New("missing street"), "street is required", speedrail.WithField("street"))),
+			),
+			speedrail.If(
+				func(model focusTestAddress) bool {
+					return model.City == ""
+				},
+				speedrail.ThrowError[any, focusTestAddress](speedrail.NewError(errors.New("missing city"), http.StatusBadRequest, "city is required")),
+			),
+		),
+	)
+}
+
+func (suite *SpeedrailFocusTestSuite) TestFocus() {
+	plan := speedrail.Plan(
+		speedrail.Focus(
+			"address",
+			func(model focusTestUser) focusTestAddress {
+				return model.Address
+			},
+			func(model focusTestUser, address focusTestAddress) focusTestUser {
+				model.Address = address
+				return model
+			},
+			focusTestAddressPlan(),
+		),
+	)
+
+	_, model, err := plan.Execute(context.Background(), nil, focusTestUser{Name: "john", Address: focusTestAddress{Street: "Main Street", City: "stockholm"}})
+	suite.NoError(err)
+	suite.Equal("STOCKHOLM", model.Address.City)
+	suite.Equal("john", model.Name)
+
+	_, model, err = plan.Execute(context.Background(), nil, focusTestUser{Name: "john"})
+	suite.Error(err)
+	suite.Equal(http.StatusBadRequest, err.StatusCode())
+	suite.Equal("street is required; city is required", err.Error())
+	suite.Equal(map[string][]string{
+		"address.street": {"street is required"},
+	}, speedrail.FieldErrors(err))
+	suite.Equal(speedrail.CategoryValidation, speedrail.CategoryOf(err))
+	suite.Require().Len(err.Trail(), 2)
+	suite.Equal("", err.Trail()[1].Field)
+	suite.True(strings.HasPrefix(err.Trail()[0].StrategyName, "address: github.com/Kansuler/speedrail_test."))
+
+	problem := speedrail.NewProblem(err, speedrail.WithProblemTrail(true))
+	suite.True(strings.HasPrefix(problem.Errors[0].Strategy, "address: speedrail_test."))
+}
+
+func (suite *SpeedrailFocusTestSuite) TestFocusIndex() {
+	items := speedrail.Plan(
+		speedrail.ThrowError[any, []string](speedrail.NewError(nil, http.StatusBadRequest, "invalid name", speedrail.WithField("[0].name"))),
+	)
+
+	plan := speedrail.Plan(
+		speedrail.Focus(
+			"items",
+			func(model map[string][]string) []string {
+				return model["items"]
+			},
+			func(model map[string][]string, items []string) map[string][]string {
+				model["items"] = items
+				return model
+			},
+			items,
+		),
+	)
+
+	_, _, err := plan.Execute(context.Background(), nil, map[string][]string{})
+	suite.Error(err)
+	suite.Equal("items[0].name", err.Trail()[0].Field)
+}
+
+type focusTestGreeter interface {
+	Greeting() string
+}
+
+type focusTestContainer struct {
+	greeting string
+}
+
+func (c focusTestContainer) Greeting() string {
+	return c.greeting
+}
+
+func (suite *SpeedrailFocusTestSuite) TestWithContainer() {
+	greet := speedrail.Plan(
+		func(ctx context.Context, container focusTestGreeter, model string) (context.Context, string, speedrail.Error) {
+			return ctx, container.Greeting() + " " + model, nil
+		},
+	)
+
+	plan := speedrail.Plan(
+		speedrail.WithContainer(func(container *focusTestContainer) focusTestGreeter {
+			return container
+		}, greet),
+	)
+
+	_, model, err := plan.Execute(context.Background(), &focusTestContainer{greeting: "hello"}, "john")
+	suite.NoError(err)
+	suite.Equal("hello john", model)
+}
+
+func TestSpeedrailFocusTestSuite(t *testing.T) {
+	suite.Run(t, new(SpeedrailFocusTestSuite))
+}
@@ -0,0 +1,344 @@
+// Package grpcx adapts speedrail plans and errors to gRPC. HTTP status codes of errors are mapped to gRPC codes, unless
+// an explicit code is set on the error with WithCode.
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Kansuler/speedrail"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+	"net/http"
+)
+
+// Coder is implemented by errors that carry an explicit gRPC code.
+type Coder interface {
+	GRPCCode() codes.Code
+}
+
+// codedError is a speedrail error with an explicit gRPC code.
+type codedError struct {
+	err  speedrail.Error
+	code codes.Code
+}
+
+// Type check that codedError implements speedrail.Error, speedrail.PolicyMerger, speedrail.TrailMapper,
+// speedrail.Redactor, speedrail.Localizer and Coder interface
+var (
+	_ speedrail.Error        = codedError{}
+	_ speedrail.PolicyMerger = codedError{}
+	_ speedrail.TrailMapper  = codedError{}
+	_ speedrail.Redactor     = codedError{}
+	_ speedrail.Localizer    = codedError{}
+	_ Coder                  = codedError{}
+)
+
+// WithCode will return err with an explicit gRPC code, that is used instead of mapping the status code of the error.
+// The code is kept when other errors are merged into err.
+func WithCode(err speedrail.Error, code codes.Code) speedrail.Error {
+	return codedError{err: err, code: code}
+}
+
+// GRPCCode returns the explicit gRPC code of the error.
+func (e codedError) GRPCCode() codes.Code {
+	return e.code
+}
+
+// Error returns the error message.
+func (e codedError) Error() string {
+	return e.err.Error()
+}
+
+// MarshalJSON returns the JSON encoding of the error.
+func (e codedError) MarshalJSON() ([]byte, error) {
+	return e.err.MarshalJSON()
+}
+
+// Trail returns the error trail.
+func (e codedError) Trail() speedrail.Trail {
+	return e.err.Trail()
+}
+
+// Merge will merge two errors together, and keep the gRPC code.
+func (e codedError) Merge(err speedrail.Error) speedrail.Error {
+	return codedError{err: e.err.Merge(err), code: e.code}
+}
+
+// MergeWithPolicy will merge two errors together with policy, and keep the gRPC code.
+func (e codedError) MergeWithPolicy(err speedrail.Error, policy speedrail.MergePolicy) speedrail.Error {
+	return codedError{err: speedrail.MergeErrors(policy, e.err, err), code: e.code}
+}
+
+// MapTrail rewrites the entries of the trail, and keeps the gRPC code.
+func (e codedError) MapTrail(fn func(speedrail.ErrorWithTrail) speedrail.ErrorWithTrail) speedrail.Error {
+	return codedError{err: speedrail.MapTrail(e.err, fn), code: e.code}
+}
+
+// StatusCode returns the status code of the error.
+func (e codedError) StatusCode() int {
+	return e.err.StatusCode()
+}
+
+// Redact will return a view of the error that is safe to show to a client, and keeps the gRPC code.
+func (e codedError) Redact(policy speedrail.RedactionPolicy) speedrail.Error {
+	return codedError{err: speedrail.Redact(e.err, policy), code: e.code}
+}
+
+// Localize will return the error with the outgoing messages translated, and keeps the gRPC code.
+func (e codedError) Localize(ctx context.Context, translator speedrail.Translator) speedrail.Error {
+	return codedError{err: speedrail.Localize(ctx, translator, e.err), code: e.code}
+}
+
+// Unwrap will return the underlying speedrail error.
+func (e codedError) Unwrap() error {
+	return e.err
+}
+
+// FromHTTPStatus maps a HTTP status code to a gRPC code.
+func FromHTTPStatus(statusCode int) codes.Code {
+	if code, ok := mapHTTPStatus(statusCode); ok {
+		return code
+	}
+
+	switch {
+	case statusCode >= 400 && statusCode < 500:
+		return codes.InvalidArgument
+	case statusCode >= 500 && statusCode < 600:
+		return codes.Internal
+	}
+
+	return codes.Unknown
+}
+
+// mapHTTPStatus maps a HTTP status code that has a corresponding gRPC code, and reports if it has one.
+func mapHTTPStatus(statusCode int) (codes.Code, bool) {
+	switch statusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent:
+		return codes.OK, true
+	case http.StatusBadRequest:
+		return codes.InvalidArgument, true
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated, true
+	case http.StatusForbidden:
+		return codes.PermissionDenied, true
+	case http.StatusNotFound:
+		return codes.NotFound, true
+	case http.StatusConflict:
+		return codes.AlreadyExists, true
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition, true
+	case http.StatusRequestedRangeNotSatisfiable:
+		return codes.OutOfRange, true
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted, true
+	case speedrail.StatusClientClosedRequest:
+		return codes.Canceled, true
+	case http.StatusNotImplemented:
+		return codes.Unimplemented, true
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable, true
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded, true
+	}
+
+	return codes.Unknown, false
+}
+
+// ToHTTPStatus maps a gRPC code to a HTTP status code.
+func ToHTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return speedrail.StatusClientClosedRequest
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusInternalServerError
+}
+
+// FromCategory maps the category of an error to a gRPC code, an empty category is mapped to codes.Unknown.
+func FromCategory(category speedrail.Category) codes.Code {
+	switch category {
+	case speedrail.CategoryValidation:
+		return codes.InvalidArgument
+	case speedrail.CategoryNotFound:
+		return codes.NotFound
+	case speedrail.CategoryConflict:
+		return codes.AlreadyExists
+	case speedrail.CategoryUnauthorized:
+		return codes.Unauthenticated
+	case speedrail.CategoryTransient:
+		return codes.Unavailable
+	case speedrail.CategoryInternal:
+		return codes.Internal
+	}
+
+	return codes.Unknown
+}
+
+// Code returns the gRPC code of err, which is the explicit code if the error has one, otherwise the status code of the
+// error if it has a corresponding gRPC code, such as 504 for codes.DeadlineExceeded. Errors with a status code of zero,
+// or a status code without a corresponding gRPC code, are mapped by their category with FromCategory, or by their status
+// code with FromHTTPStatus if they have no category.
+func Code(err speedrail.Error) codes.Code {
+	var coder Coder
+	if errors.As(err, &coder) {
+		return coder.GRPCCode()
+	}
+
+	if code, ok := mapHTTPStatus(err.StatusCode()); ok {
+		return code
+	}
+
+	if category := speedrail.CategoryOf(err); category != "" {
+		return FromCategory(category)
+	}
+
+	return FromHTTPStatus(err.StatusCode())
+}
+
+// config holds the settings used when converting errors to a status.
+type config struct {
+	trailDetails bool
+}
+
+// Option configures how errors are converted to a status.
+type Option func(*config)
+
+// WithTrailDetails decides if the trail of the error is added as debug info to the details of the status. By default,
+// the trail is only added in debug builds, as it holds internal errors that should not be exposed in production.
+func WithTrailDetails(show bool) Option {
+	return func(config *config) {
+		config.trailDetails = show
+	}
+}
+
+// Status will return the gRPC status of err, with the outgoing message of the error as message. The details of the
+// error, see speedrail.Details, are added to the status as a BadRequest with the field violations, and an ErrorInfo with
+// the error code and metadata. The trail is added as DebugInfo when enabled with WithTrailDetails.
+func Status(err speedrail.Error, options ...Option) *status.Status {
+	config := config{trailDetails: speedrail.Debug}
+	for _, option := range options {
+		option(&config)
+	}
+
+	var details []protoiface.MessageV1
+	badRequest := &errdetails.BadRequest{}
+	var errorInfo *errdetails.ErrorInfo
+	for _, detail := range speedrail.Details(err) {
+		if detail.Field != "" {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       detail.Field,
+				Description: detail.Message,
+			})
+		}
+
+		if detail.Code != "" && errorInfo == nil {
+			errorInfo = &errdetails.ErrorInfo{Reason: detail.Code}
+			for key, value := range detail.Metadata {
+				if errorInfo.Metadata == nil {
+					errorInfo.Metadata = map[string]string{}
+				}
+
+				errorInfo.Metadata[key] = fmt.Sprint(value)
+			}
+		}
+	}
+
+	if len(badRequest.FieldViolations) > 0 {
+		details = append(details, badRequest)
+	}
+
+	if errorInfo != nil {
+		details = append(details, errorInfo)
+	}
+
+	if config.trailDetails {
+		debugInfo := &errdetails.DebugInfo{Detail: err.Error()}
+		for index, entry := range err.Trail() {
+			debugInfo.StackEntries = append(debugInfo.StackEntries, fmt.Sprintf("[%d]%s: %s", index+1, entry.StrategyName, entry.Error))
+		}
+
+		details = append(details, debugInfo)
+	}
+
+	st := status.New(Code(err), err.Error())
+	if len(details) == 0 {
+		return st
+	}
+
+	withDetails, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		return st
+	}
+
+	return withDetails
+}
+
+// FromStatus will return a speedrail error for a gRPC status, with the code of the status kept as explicit code.
+func FromStatus(st *status.Status) speedrail.Error {
+	return WithCode(speedrail.NewError(st.Err(), ToHTTPStatus(st.Code()), st.Message()), st.Code())
+}
+
+// UnaryServerInterceptor returns an interceptor that converts speedrail errors returned by handlers to a gRPC status.
+func UnaryServerInterceptor(options ...Option) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		var speedrailErr speedrail.Error
+		if err != nil && errors.As(err, &speedrailErr) {
+			return resp, Status(speedrailErr, options...).Err()
+		}
+
+		return resp, err
+	}
+}
+
+// Unary returns a unary method implementation that decodes the request into a model, executes the plan and encodes the
+// resulting model as the response. Errors are returned as a gRPC status.
+func Unary[C, M, Req, Resp any](plan speedrail.Speedrail[C, M], decode func(context.Context, Req) (M, error), encode func(M) (Resp, error), container C, options ...Option) func(context.Context, Req) (Resp, error) {
+	return func(ctx context.Context, req Req) (Resp, error) {
+		var resp Resp
+		model, err := decode(ctx, req)
+		if err != nil {
+			var speedrailErr speedrail.Error
+			if !errors.As(err, &speedrailErr) {
+				speedrailErr = speedrail.NewError(err, http.StatusBadRequest, "invalid request")
+			}
+
+			return resp, Status(speedrailErr, options...).Err()
+		}
+
+		_, model, speedrailErr := plan.Execute(ctx, container, model)
+		if speedrailErr != nil {
+			return resp, Status(speedrailErr, options...).Err()
+		}
+
+		if resp, err = encode(model); err != nil {
+			return resp, Status(speedrail.NewError(err, http.StatusInternalServerError, "could not encode response"), options...).Err()
+		}
+
+		return resp, nil
+	}
+}
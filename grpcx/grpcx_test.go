@@ -0,0 +1,252 @@
+package grpcx_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Kansuler/speedrail"
+	"github.com/Kansuler/speedrail/grpcx"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type GrpcxTestSuite struct {
+	suite.Suite
+}
+
+type grpcxTestModel struct {
+	Name string
+}
+
+// grpcxTestService is a hand written service description, so that the test does not depend on generated code.
+type grpcxTestService interface {
+	Greet(context.Context, *wrapperspb.StringValue) (*wrapperspb.StringValue, error)
+}
+
+type grpcxTestServer struct {
+	greet func(context.Context, *wrapperspb.StringValue) (*wrapperspb.StringValue, error)
+}
+
+func (s grpcxTestServer) Greet(ctx context.Context, req *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+	return s.greet(ctx, req)
+}
+
+var grpcxTestServiceDesc = grpc.ServiceDesc{
+	ServiceName: "test.Greeter",
+	HandlerType: (*grpcxTestService)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Greet",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				req := new(wrapperspb.StringValue)
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+
+				handler := func(ctx context.Context, req any) (any, error) {
+					return srv.(grpcxTestService).Greet(ctx, req.(*wrapperspb.StringValue))
+				}
+
+				if interceptor == nil {
+					return handler(ctx, req)
+				}
+
+				return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Greeter/Greet"}, handler)
+			},
+		},
+	},
+}
+
+func (suite *GrpcxTestSuite) dial(server grpcxTestServer, options ...grpc.ServerOption) *grpc.ClientConn {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer(options...)
+	grpcServer.RegisterService(&grpcxTestServiceDesc, server)
+	go func() {
+		_ = grpcServer.Serve(listener)
+	}()
+	suite.T().Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	suite.Require().NoError(err)
+	suite.T().Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return conn
+}
+
+func (suite *GrpcxTestSuite) TestCodeMapping() {
+	suite.Equal(codes.NotFound, grpcx.FromHTTPStatus(http.StatusNotFound))
+	suite.Equal(codes.InvalidArgument, grpcx.FromHTTPStatus(http.StatusUnprocessableEntity))
+	suite.Equal(codes.Internal, grpcx.FromHTTPStatus(http.StatusBadGateway))
+	suite.Equal(http.StatusNotFound, grpcx.ToHTTPStatus(codes.NotFound))
+	suite.Equal(http.StatusServiceUnavailable, grpcx.ToHTTPStatus(codes.Unavailable))
+	suite.Equal(http.StatusInternalServerError, grpcx.ToHTTPStatus(codes.DataLoss))
+
+	err := speedrail.NewError(errors.New("exists"), http.StatusConflict, "user exists")
+	suite.Equal(codes.AlreadyExists, grpcx.Code(err))
+
+	coded := grpcx.WithCode(err, codes.Aborted).Merge(speedrail.NewError(nil, http.StatusInternalServerError, "other"))
+	suite.Equal(codes.Aborted, grpcx.Code(coded))
+	suite.Equal(http.StatusInternalServerError, coded.StatusCode())
+	suite.Equal("user exists; other", coded.Error())
+	suite.Equal(2, len(coded.Trail()))
+
+	public := speedrail.Public(grpcx.WithCode(speedrail.NewError(errors.New("pq: failed"), http.StatusInternalServerError, "failed"), codes.DataLoss))
+	suite.Equal(codes.DataLoss, grpcx.Code(public))
+	suite.Equal("Internal Server Error", public.Error())
+
+	suite.Equal(codes.Unavailable, grpcx.Code(speedrail.Transient(nil, "unavailable")))
+	suite.Equal(codes.DeadlineExceeded, grpcx.Code(speedrail.FromError(context.DeadlineExceeded)))
+	suite.Equal(codes.Internal, grpcx.Code(speedrail.Internal(nil, "internal")))
+	suite.Equal(codes.Unavailable, grpcx.Code(speedrail.NewError(nil, 0, "unavailable", speedrail.WithCategory(speedrail.CategoryTransient))))
+	suite.Equal(codes.Unavailable, grpcx.Code(speedrail.NewError(nil, http.StatusBadGateway, "bad gateway", speedrail.WithCategory(speedrail.CategoryTransient))))
+	suite.Equal(codes.NotFound, grpcx.Code(speedrail.NotFound(nil, "not found").Merge(speedrail.NewError(nil, http.StatusBadRequest, "other"))))
+
+	st := grpcx.Status(err, grpcx.WithTrailDetails(true))
+	suite.Equal(codes.AlreadyExists, st.Code())
+	suite.Equal("user exists", st.Message())
+	suite.Len(st.Details(), 1)
+
+	back := grpcx.FromStatus(st)
+	suite.Equal(http.StatusConflict, back.StatusCode())
+	suite.Equal(codes.AlreadyExists, grpcx.Code(back))
+	suite.Equal("user exists", back.Error())
+}
+
+func (suite *GrpcxTestSuite) TestFocus() {
+	plan := speedrail.Plan(
+		speedrail.Focus(
+			"name",
+			func(model grpcxTestModel) string {
+				return model.Name
+			},
+			func(model grpcxTestModel, name string) grpcxTestModel {
+				model.Name = name
+				return model
+			},
+			speedrail.Plan(speedrail.ThrowError[any, string](grpcx.WithCode(speedrail.NewError(nil, http.StatusBadRequest, "name is taken"), codes.FailedPrecondition))),
+		),
+	)
+
+	_, _, err := plan.Execute(context.Background(), nil, grpcxTestModel{})
+	suite.Equal(codes.FailedPrecondition, grpcx.Code(err))
+	suite.True(strings.HasPrefix(err.Trail()[0].StrategyName, "name: "))
+}
+
+func (suite *GrpcxTestSuite) TestLocalize() {
+	catalog := speedrail.NewCatalog("sv")
+	suite.Require().NoError(catalog.Add("sv", map[string]string{"name.taken": "Namnet är upptaget"}))
+
+	err := grpcx.WithCode(speedrail.NewError(nil, http.StatusBadRequest, "name is taken", speedrail.WithMessageKey("name.taken", nil)), codes.FailedPrecondition)
+	localized := speedrail.Localize(context.Background(), catalog, err)
+	suite.Equal(codes.FailedPrecondition, grpcx.Code(localized))
+	suite.Equal("Namnet är upptaget", localized.Error())
+}
+
+func (suite *GrpcxTestSuite) TestStatusDetails() {
+	err := speedrail.NewError(nil, http.StatusBadRequest, "invalid email", speedrail.WithField("email"), speedrail.WithCode("invalid_email"), speedrail.WithMeta("max", 254)).
+		Merge(speedrail.NewError(nil, http.StatusBadRequest, "missing name", speedrail.WithField("name")))
+
+	st := grpcx.Status(err, grpcx.WithTrailDetails(false))
+	suite.Equal(codes.InvalidArgument, st.Code())
+	suite.Require().Len(st.Details(), 2)
+
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	suite.Require().True(ok)
+	suite.Require().Len(badRequest.GetFieldViolations(), 2)
+	suite.Equal("email", badRequest.GetFieldViolations()[0].GetField())
+	suite.Equal("invalid email", badRequest.GetFieldViolations()[0].GetDescription())
+	suite.Equal("name", badRequest.GetFieldViolations()[1].GetField())
+
+	errorInfo, ok := st.Details()[1].(*errdetails.ErrorInfo)
+	suite.Require().True(ok)
+	suite.Equal("invalid_email", errorInfo.GetReason())
+	suite.Equal(map[string]string{"max": "254"}, errorInfo.GetMetadata())
+
+	st = grpcx.Status(err, grpcx.WithTrailDetails(true))
+	suite.Require().Len(st.Details(), 3)
+	_, ok = st.Details()[2].(*errdetails.DebugInfo)
+	suite.True(ok)
+}
+
+func (suite *GrpcxTestSuite) TestUnary() {
+	plan := speedrail.Plan(
+		speedrail.If(
+			func(model grpcxTestModel) bool {
+				return model.Name == ""
+			},
+			speedrail.ThrowError[any, grpcxTestModel](speedrail.NewError(errors.New("missing name"), http.StatusBadRequest, "missing name")),
+		),
+		func(ctx context.Context, container any, model grpcxTestModel) (context.Context, grpcxTestModel, speedrail.Error) {
+			model.Name = "hello " + strings.ToUpper(model.Name)
+			return ctx, model, nil
+		},
+	)
+
+	greet := grpcx.Unary(
+		plan,
+		func(ctx context.Context, req *wrapperspb.StringValue) (grpcxTestModel, error) {
+			return grpcxTestModel{Name: req.GetValue()}, nil
+		},
+		func(model grpcxTestModel) (*wrapperspb.StringValue, error) {
+			return wrapperspb.String(model.Name), nil
+		},
+		nil,
+		grpcx.WithTrailDetails(true),
+	)
+
+	conn := suite.dial(grpcxTestServer{greet: greet})
+
+	resp := new(wrapperspb.StringValue)
+	err := conn.Invoke(context.Background(), "/test.Greeter/Greet", wrapperspb.String("john"), resp)
+	suite.NoError(err)
+	suite.Equal("hello JOHN", resp.GetValue())
+
+	err = conn.Invoke(context.Background(), "/test.Greeter/Greet", wrapperspb.String(""), resp)
+	st, ok := status.FromError(err)
+	suite.True(ok)
+	suite.Equal(codes.InvalidArgument, st.Code())
+	suite.Equal("missing name", st.Message())
+	suite.Require().Len(st.Details(), 1)
+	debugInfo, ok := st.Details()[0].(*errdetails.DebugInfo)
+	suite.True(ok)
+	suite.Len(debugInfo.GetStackEntries(), 1)
+	suite.Contains(debugInfo.GetStackEntries()[0], "missing name")
+}
+
+func (suite *GrpcxTestSuite) TestUnaryServerInterceptor() {
+	conn := suite.dial(
+		grpcxTestServer{greet: func(ctx context.Context, req *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+			return nil, speedrail.NewError(errors.New("no such user"), http.StatusNotFound, "user not found")
+		}},
+		grpc.UnaryInterceptor(grpcx.UnaryServerInterceptor(grpcx.WithTrailDetails(false))),
+	)
+
+	err := conn.Invoke(context.Background(), "/test.Greeter/Greet", wrapperspb.String("john"), new(wrapperspb.StringValue))
+	st, ok := status.FromError(err)
+	suite.True(ok)
+	suite.Equal(codes.NotFound, st.Code())
+	suite.Equal("user not found", st.Message())
+	suite.Empty(st.Details())
+}
+
+func TestGrpcxTestSuite(t *testing.T) {
+	suite.Run(t, new(GrpcxTestSuite))
+}
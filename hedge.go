@@ -0,0 +1,102 @@
+package speedrail
+
+import (
+	"context"
+	"time"
+)
+
+// valueContext takes its values from one context, while deadline and cancellation are inherited from another. It is
+// used to hand back a context returned by a strategy that was executed with a derived context which is later cancelled.
+// The warning collector is taken from the inherited context, as the derived context collects the warnings of a single
+// attempt.
+type valueContext struct {
+	context.Context
+	values context.Context
+}
+
+// Value returns the value associated with key from the values context.
+func (c valueContext) Value(key any) any {
+	if _, ok := key.(warningsKey); ok {
+		return c.Context.Value(key)
+	}
+
+	return c.values.Value(key)
+}
+
+// Hedge executes a strategy, and if it has not finished within delay a second copy of the strategy is started. The
+// result of whichever attempt first finishes successfully is returned and the other attempt is cancelled through its
+// context. Should the first attempt fail before the delay has passed, its error is returned, unless the error is
+// retryable, see Retryable, in which case the second attempt is started right away. If both attempts fail the errors are
+// merged together. Warnings emitted by an attempt are only kept if its result is returned, so that an attempt that is
+// cancelled does not add to the warnings of the plan.
+//
+// Both attempts receive their own copy of the model, so the strategy must not share mutable state through it.
+func Hedge[C, M any](delay time.Duration, strategy Strategy[C, M]) Strategy[C, M] {
+	return validated("Hedge", namedProblems("strategy", strategy), func(ctx context.Context, container C, model M) (context.Context, M, Error) {
+		type attempt struct {
+			ctx      context.Context
+			model    M
+			err      Error
+			warnings *warningCollector
+		}
+
+		results := make(chan attempt, 2)
+		var cancels []context.CancelFunc
+		defer func() {
+			for _, cancel := range cancels {
+				cancel()
+			}
+		}()
+
+		launch := func() {
+			attemptCtx, cancel := context.WithCancel(ctx)
+			cancels = append(cancels, cancel)
+			attemptCtx, warnings := forkWarnings(attemptCtx)
+			go func() {
+				resultCtx, resultModel, err := run(attemptCtx, strategy, container, model)
+				results <- attempt{ctx: resultCtx, model: resultModel, err: err, warnings: warnings}
+			}()
+		}
+
+		launch()
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		hedge := timer.C
+
+		var resultErr Error
+		var failed []*warningCollector
+		resultModel := model
+		for running := 1; running > 0; {
+			select {
+			case <-hedge:
+				hedge = nil
+				launch()
+				running++
+			case result := <-results:
+				running--
+				if result.err == nil {
+					keepWarnings(ctx, result.warnings)
+					return valueContext{Context: ctx, values: result.ctx}, result.model, nil
+				}
+
+				resultModel = result.model
+				resultErr = MergeErrors(mergePolicy(ctx), resultErr, result.err)
+				failed = append(failed, result.warnings)
+
+				// Only errors that are known to be retryable are worth a second attempt before the delay has passed.
+				if hedge != nil && Retryable(result.err) {
+					hedge = nil
+					launch()
+					running++
+				}
+			}
+		}
+
+		// The errors of every attempt are returned, and so are their warnings.
+		for _, warnings := range failed {
+			keepWarnings(ctx, warnings)
+		}
+
+		return ctx, resultModel, resultErr
+	})
+}
@@ -0,0 +1,153 @@
+package speedrail_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Kansuler/speedrail"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type SpeedrailHedgeTestSuite struct {
+	suite.Suite
+}
+
+type hedgeTestModel struct {
+	Attempt int32
+}
+
+type hedgeTestKey struct{}
+
+func (suite *SpeedrailHedgeTestSuite) TestFirstAttemptWins() {
+	var calls int32
+	plan := speedrail.Plan(
+		speedrail.Hedge(time.Second, func(ctx context.Context, container any, model hedgeTestModel) (context.Context, hedgeTestModel, speedrail.Error) {
+			model.Attempt = atomic.AddInt32(&calls, 1)
+			return context.WithValue(ctx, hedgeTestKey{}, "value"), model, nil
+		}),
+	)
+
+	ctx, model, err := plan.Execute(context.Background(), nil, hedgeTestModel{})
+	suite.NoError(err)
+	suite.Equal(int32(1), model.Attempt)
+	suite.Equal(int32(1), atomic.LoadInt32(&calls))
+	suite.Equal("value", ctx.Value(hedgeTestKey{}))
+	suite.NoError(ctx.Err())
+}
+
+func (suite *SpeedrailHedgeTestSuite) TestSecondAttemptWins() {
+	var calls int32
+	cancelled := make(chan struct{})
+	plan := speedrail.Plan(
+		speedrail.Hedge(10*time.Millisecond, func(ctx context.Context, container any, model hedgeTestModel) (context.Context, hedgeTestModel, speedrail.Error) {
+			model.Attempt = atomic.AddInt32(&calls, 1)
+			if model.Attempt == 1 {
+				<-ctx.Done()
+				close(cancelled)
+				return ctx, model, speedrail.NewError(ctx.Err(), http.StatusGatewayTimeout, "cancelled")
+			}
+
+			return ctx, model, nil
+		}),
+	)
+
+	ctx, model, err := plan.Execute(context.Background(), nil, hedgeTestModel{})
+	suite.NoError(err)
+	suite.Equal(int32(2), model.Attempt)
+	suite.NoError(ctx.Err())
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		suite.Fail("first attempt was not cancelled")
+	}
+}
+
+func (suite *SpeedrailHedgeTestSuite) TestWarnings() {
+	var calls int32
+	warn := func(message string) speedrail.Strategy[any, hedgeTestModel] {
+		return func(ctx context.Context, container any, model hedgeTestModel) (context.Context, hedgeTestModel, speedrail.Error) {
+			speedrail.Warn(ctx, speedrail.Warning{Message: message})
+			return ctx, model, nil
+		}
+	}
+
+	plan := speedrail.Plan(
+		warn("before"),
+		speedrail.Hedge(10*time.Millisecond, func(ctx context.Context, container any, model hedgeTestModel) (context.Context, hedgeTestModel, speedrail.Error) {
+			model.Attempt = atomic.AddInt32(&calls, 1)
+			if model.Attempt == 1 {
+				speedrail.Warn(ctx, speedrail.Warning{Message: "cancelled attempt"})
+				<-ctx.Done()
+				return ctx, model, speedrail.NewError(ctx.Err(), http.StatusGatewayTimeout, "cancelled")
+			}
+
+			speedrail.Warn(ctx, speedrail.Warning{Message: "winning attempt"})
+			return ctx, model, nil
+		}),
+		warn("after"),
+	)
+
+	ctx, _, err := plan.Execute(context.Background(), nil, hedgeTestModel{})
+	suite.NoError(err)
+	suite.Equal([]speedrail.Warning{{Message: "before"}, {Message: "winning attempt"}, {Message: "after"}}, speedrail.Warnings(ctx))
+}
+
+func (suite *SpeedrailHedgeTestSuite) TestBothAttemptsFail() {
+	var calls int32
+	plan := speedrail.Plan(
+		speedrail.Hedge(time.Second, func(ctx context.Context, container any, model hedgeTestModel) (context.Context, hedgeTestModel, speedrail.Error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				return ctx, model, speedrail.Transient(errors.New("error 1"), "error 1")
+			}
+
+			return ctx, model, speedrail.NewError(errors.New("error 2"), http.StatusServiceUnavailable, "error 2")
+		}),
+	)
+
+	start := time.Now()
+	_, _, err := plan.Execute(context.Background(), nil, hedgeTestModel{})
+	suite.Error(err)
+	suite.Less(time.Since(start), time.Second)
+	suite.Equal(int32(2), atomic.LoadInt32(&calls))
+	suite.Equal(http.StatusServiceUnavailable, err.StatusCode())
+	suite.Equal("error 1; error 2", err.Error())
+	suite.Equal(2, len(err.Trail()))
+}
+
+func (suite *SpeedrailHedgeTestSuite) TestFailsBeforeDelay() {
+	var calls int32
+	plan := speedrail.Plan(
+		speedrail.Hedge(time.Second, func(ctx context.Context, container any, model hedgeTestModel) (context.Context, hedgeTestModel, speedrail.Error) {
+			atomic.AddInt32(&calls, 1)
+			return ctx, model, speedrail.NewError(errors.New("bad"), http.StatusBadRequest, "bad")
+		}),
+	)
+
+	start := time.Now()
+	_, _, err := plan.Execute(context.Background(), nil, hedgeTestModel{})
+	suite.Error(err)
+	suite.Less(time.Since(start), time.Second)
+	suite.Equal(int32(1), atomic.LoadInt32(&calls))
+	suite.Equal(http.StatusBadRequest, err.StatusCode())
+	suite.Equal("bad", err.Error())
+}
+
+func (suite *SpeedrailHedgeTestSuite) TestNoContextReturned() {
+	plan := speedrail.Plan(
+		speedrail.Hedge(time.Second, func(ctx context.Context, container any, model hedgeTestModel) (context.Context, hedgeTestModel, speedrail.Error) {
+			return nil, model, nil
+		}),
+	)
+
+	_, _, err := plan.Execute(context.Background(), nil, hedgeTestModel{})
+	suite.Error(err)
+	suite.ErrorIs(err, speedrail.ErrNoContextReturned)
+}
+
+func TestSpeedrailHedgeTestSuite(t *testing.T) {
+	suite.Run(t, new(SpeedrailHedgeTestSuite))
+}
@@ -0,0 +1,384 @@
+// Package httpx adapts speedrail plans to net/http. A request is decoded into the model, the plan is executed and the
+// resulting model, or error, is written as the response.
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/Kansuler/speedrail"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Decoder decodes a request into a model.
+type Decoder[M any] func(*http.Request) (M, error)
+
+// Encoder writes a model as the response. The status code is written by the encoder, and it should set the content type
+// of the response.
+type Encoder[M any] func(http.ResponseWriter, *http.Request, int, M) error
+
+// ErrorEncoder writes an error as the response.
+type ErrorEncoder func(http.ResponseWriter, *http.Request, speedrail.Error)
+
+// mediaEncoder is an encoder registered for a media type.
+type mediaEncoder[M any] struct {
+	mediaType string
+	encode    Encoder[M]
+}
+
+// config holds the settings of a handler.
+type config[M any] struct {
+	decoders        map[string]Decoder[M]
+	encoders        []mediaEncoder[M]
+	encodeError     ErrorEncoder
+	redaction       speedrail.RedactionPolicy
+	trail           bool
+	translator      speedrail.Translator
+	requestHeaders  func(http.Header, M) M
+	responseHeaders func(http.Header, M)
+	status          int
+}
+
+// Option configures the behaviour of a handler.
+type Option[M any] func(*config[M])
+
+// WithDecoder registers a decoder that is used for requests with the given media type as Content-Type.
+func WithDecoder[M any](mediaType string, decoder Decoder[M]) Option[M] {
+	return func(config *config[M]) {
+		config.decoders[mediaType] = decoder
+	}
+}
+
+// WithEncoder registers an encoder that is used when the media type is preferred by the Accept header of the request.
+func WithEncoder[M any](mediaType string, encoder Encoder[M]) Option[M] {
+	return func(config *config[M]) {
+		config.encoders = append(config.encoders, mediaEncoder[M]{mediaType: mediaType, encode: encoder})
+	}
+}
+
+// WithErrorEncoder replaces the encoder used to write errors, which by default writes the status code of the error and
+// its JSON encoding.
+func WithErrorEncoder[M any](encoder ErrorEncoder) Option[M] {
+	return func(config *config[M]) {
+		config.encodeError = encoder
+	}
+}
+
+// WithRedaction sets the policy used to redact errors before they are written, by default it is
+// speedrail.DefaultRedactionPolicy.
+func WithRedaction[M any](policy speedrail.RedactionPolicy) Option[M] {
+	return func(config *config[M]) {
+		config.redaction = policy
+	}
+}
+
+// WithTrail decides if the trail of errors is written in responses. By default it is not, and errors are redacted before
+// they are written, so that internal errors are never exposed unless explicitly enabled.
+func WithTrail[M any](show bool) Option[M] {
+	return func(config *config[M]) {
+		config.trail = show
+	}
+}
+
+// WithTranslator sets the translator used to localize errors before they are written, to the locales preferred by the
+// Accept-Language header of the request.
+func WithTranslator[M any](translator speedrail.Translator) Option[M] {
+	return func(config *config[M]) {
+		config.translator = translator
+	}
+}
+
+// WithRequestHeaders maps headers of the request into the model, after the request has been decoded.
+func WithRequestHeaders[M any](mapping func(http.Header, M) M) Option[M] {
+	return func(config *config[M]) {
+		config.requestHeaders = mapping
+	}
+}
+
+// WithResponseHeaders maps the model into headers of the response, after the plan has been executed successfully.
+func WithResponseHeaders[M any](mapping func(http.Header, M)) Option[M] {
+	return func(config *config[M]) {
+		config.responseHeaders = mapping
+	}
+}
+
+// WithStatus sets the status code written when the plan is executed successfully, by default it is 200.
+func WithStatus[M any](status int) Option[M] {
+	return func(config *config[M]) {
+		config.status = status
+	}
+}
+
+// Handler returns a http.Handler that decodes the request into a model, executes the plan with the context of the request
+// and writes the resulting model as the response. The given decoder and encoder are used unless another decoder or
+// encoder is registered for the media type of the request. The locales preferred by the Accept-Language header are set
+// on the context, see speedrail.WithLocale. Errors are localized, redacted, and written with the status code of the
+// error.
+func Handler[C, M any](plan speedrail.Speedrail[C, M], decode Decoder[M], encode Encoder[M], container C, options ...Option[M]) http.Handler {
+	config := config[M]{
+		decoders:    map[string]Decoder[M]{},
+		encodeError: EncodeError,
+		redaction:   speedrail.DefaultRedactionPolicy,
+		status:      http.StatusOK,
+	}
+	for _, option := range options {
+		option(&config)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if locales := acceptedLanguages(r.Header.Get("Accept-Language")); len(locales) > 0 && speedrail.Locales(r.Context()) == nil {
+			r = r.WithContext(speedrail.WithLocale(r.Context(), locales...))
+		}
+
+		model, err := config.decoder(r, decode)(r)
+		if err != nil {
+			var speedrailErr speedrail.Error
+			if !errors.As(err, &speedrailErr) {
+				speedrailErr = speedrail.NewError(err, http.StatusBadRequest, "invalid request")
+			}
+
+			config.writeError(w, r, speedrailErr)
+			return
+		}
+
+		if config.requestHeaders != nil {
+			model = config.requestHeaders(r.Header, model)
+		}
+
+		ctx, model, speedrailErr := plan.Execute(r.Context(), container, model)
+		if speedrailErr != nil {
+			config.writeError(w, r, speedrailErr)
+			return
+		}
+
+		// The model is encoded with the context of the execution, so that encoders have access to its warnings.
+		r = r.WithContext(ctx)
+
+		if config.responseHeaders != nil {
+			config.responseHeaders(w.Header(), model)
+		}
+
+		if err = config.encoder(r, encode)(w, r, config.status, model); err != nil {
+			config.writeError(w, r, speedrail.NewError(err, http.StatusInternalServerError, "could not encode response"))
+		}
+	})
+}
+
+// trailKey is the context key that marks that the trail of errors may be written in the response.
+type trailKey struct{}
+
+// ShowTrail reports if the trail of errors may be written in the response to the request, which is the case when the
+// handler is created with WithTrail. Error encoders should only write the trail if it returns true.
+func ShowTrail(r *http.Request) bool {
+	show, _ := r.Context().Value(trailKey{}).(bool)
+	return show
+}
+
+// writeError writes the error with the error encoder. The error is localized, and redacted unless the trail is shown.
+func (c config[M]) writeError(w http.ResponseWriter, r *http.Request, err speedrail.Error) {
+	err = speedrail.Localize(r.Context(), c.translator, err)
+	if c.trail {
+		r = r.WithContext(context.WithValue(r.Context(), trailKey{}, true))
+	} else {
+		err = speedrail.Redact(err, c.redaction)
+	}
+
+	c.encodeError(w, r, err)
+}
+
+// decoder returns the decoder registered for the Content-Type of the request, or fallback.
+func (c config[M]) decoder(r *http.Request, fallback Decoder[M]) Decoder[M] {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err == nil {
+		if decoder, ok := c.decoders[mediaType]; ok {
+			return decoder
+		}
+	}
+
+	if fallback == nil {
+		return DecodeJSON[M]
+	}
+
+	return fallback
+}
+
+// encoder returns the registered encoder that is most preferred by the Accept header of the request, or fallback if
+// nothing is preferred over */*.
+func (c config[M]) encoder(r *http.Request, fallback Encoder[M]) Encoder[M] {
+	for _, accepted := range acceptedValues(r.Header.Get("Accept")) {
+		if accepted == "*/*" {
+			break
+		}
+
+		for _, encoder := range c.encoders {
+			if matchMediaType(accepted, encoder.mediaType) {
+				return encoder.encode
+			}
+		}
+	}
+
+	if fallback == nil {
+		return EncodeJSON[M]
+	}
+
+	return fallback
+}
+
+// acceptedLanguages parses an Accept-Language header, and returns the locales ordered by preference.
+func acceptedLanguages(header string) []string {
+	var locales []string
+	for _, locale := range acceptedValues(header) {
+		if locale != "*" {
+			locales = append(locales, locale)
+		}
+	}
+
+	return locales
+}
+
+// acceptedValues parses an Accept or Accept-Language header, and returns the values ordered by preference.
+func acceptedValues(header string) []string {
+	type accepted struct {
+		mediaType string
+		quality   float64
+	}
+
+	var mediaTypes []accepted
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+
+		if quality > 0 {
+			mediaTypes = append(mediaTypes, accepted{mediaType: mediaType, quality: quality})
+		}
+	}
+
+	sort.SliceStable(mediaTypes, func(a, b int) bool {
+		return mediaTypes[a].quality > mediaTypes[b].quality
+	})
+
+	result := make([]string, len(mediaTypes))
+	for i, mediaType := range mediaTypes {
+		result[i] = mediaType.mediaType
+	}
+
+	return result
+}
+
+// matchMediaType checks if a media type from an Accept header, which may be a wildcard such as text/*, matches mediaType.
+func matchMediaType(accepted, mediaType string) bool {
+	if accepted == mediaType {
+		return true
+	}
+
+	prefix, ok := strings.CutSuffix(accepted, "/*")
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
+
+// DecodeJSON decodes the JSON body of the request into a model. An empty body results in the zero value of the model.
+func DecodeJSON[M any](r *http.Request) (M, error) {
+	var model M
+	if r.Body == nil || r.Body == http.NoBody {
+		return model, nil
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&model)
+	if errors.Is(err, io.EOF) {
+		return model, nil
+	}
+
+	return model, err
+}
+
+// EncodeJSON writes the model as JSON.
+func EncodeJSON[M any](w http.ResponseWriter, _ *http.Request, status int, model M) error {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err = w.Write(data)
+	return err
+}
+
+// Response is the JSON envelope written by EncodeJSONWithWarnings.
+type Response[M any] struct {
+	Data     M                   `json:"data"`
+	Warnings []speedrail.Warning `json:"warnings,omitempty"`
+}
+
+// EncodeJSONWithWarnings writes the model as JSON in a Response, next to the warnings emitted during the execution of the
+// plan.
+func EncodeJSONWithWarnings[M any](w http.ResponseWriter, r *http.Request, status int, model M) error {
+	return EncodeJSON(w, r, status, Response[M]{Data: model, Warnings: speedrail.Warnings(r.Context())})
+}
+
+// EncodeError writes the status code of the error and its JSON encoding, together with the trail if ShowTrail reports so.
+// Errors without a status code are written with the default status code of their category.
+func EncodeError(w http.ResponseWriter, r *http.Request, err speedrail.Error) {
+	data, marshalErr := err.MarshalJSON()
+	if marshalErr == nil && ShowTrail(r) {
+		data, marshalErr = withTrail(data, err.Trail())
+	}
+
+	if marshalErr != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	status := err.StatusCode()
+	if status == 0 {
+		status = speedrail.CategoryOf(err).StatusCode()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
+
+// withTrail adds the trail to the JSON object data.
+func withTrail(data []byte, trail speedrail.Trail) ([]byte, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(trail)
+	if err != nil {
+		return nil, err
+	}
+
+	object["trail"] = encoded
+	return json.Marshal(object)
+}
+
+// EncodeProblem writes the error as problem details, as defined by RFC 7807, with the path of the request as instance.
+// The trail is rendered in the errors extension if ShowTrail reports so. It can be used with WithErrorEncoder.
+func EncodeProblem(w http.ResponseWriter, r *http.Request, err speedrail.Error) {
+	problem := speedrail.NewProblem(err, speedrail.WithProblemInstance(r.URL.Path), speedrail.WithProblemTrail(ShowTrail(r)))
+	data, marshalErr := json.Marshal(problem)
+	if marshalErr != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", speedrail.ProblemContentType)
+	w.WriteHeader(problem.Status)
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,211 @@
+package httpx_test
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"github.com/Kansuler/speedrail"
+	"github.com/Kansuler/speedrail/httpx"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type HttpxTestSuite struct {
+	suite.Suite
+}
+
+type httpxTestModel struct {
+	XMLName   xml.Name `json:"-" xml:"user"`
+	UserName  string   `json:"username" xml:"username"`
+	RequestID string   `json:"request_id" xml:"request_id"`
+}
+
+func httpxTestPlan() speedrail.Speedrail[any, httpxTestModel] {
+	return speedrail.Plan(
+		speedrail.If(
+			func(model httpxTestModel) bool {
+				return model.UserName == ""
+			},
+			speedrail.ThrowError[any, httpxTestModel](speedrail.NewError(errors.New("missing username"), http.StatusBadRequest, "missing username")),
+		),
+		func(ctx context.Context, container any, model httpxTestModel) (context.Context, httpxTestModel, speedrail.Error) {
+			model.UserName = strings.ToUpper(model.UserName)
+			return ctx, model, nil
+		},
+	)
+}
+
+func (suite *HttpxTestSuite) TestHandler() {
+	handler := httpx.Handler[any, httpxTestModel](httpxTestPlan(), nil, nil, nil)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"username":"john"}`)))
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal("application/json", recorder.Header().Get("Content-Type"))
+	suite.JSONEq(`{"username":"JOHN","request_id":""}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`)))
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+	suite.JSONEq(`{"error":"missing username"}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{`)))
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+	suite.JSONEq(`{"error":"invalid request"}`, recorder.Body.String())
+}
+
+func (suite *HttpxTestSuite) TestContentNegotiation() {
+	decodeXML := func(r *http.Request) (httpxTestModel, error) {
+		var model httpxTestModel
+		err := xml.NewDecoder(r.Body).Decode(&model)
+		return model, err
+	}
+
+	encodeXML := func(w http.ResponseWriter, r *http.Request, status int, model httpxTestModel) error {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(status)
+		return xml.NewEncoder(w).Encode(model)
+	}
+
+	handler := httpx.Handler[any, httpxTestModel](
+		httpxTestPlan(),
+		httpx.DecodeJSON[httpxTestModel],
+		httpx.EncodeJSON[httpxTestModel],
+		nil,
+		httpx.WithDecoder("application/xml", decodeXML),
+		httpx.WithEncoder("application/xml", encodeXML),
+		httpx.WithStatus[httpxTestModel](http.StatusCreated),
+	)
+
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`<user><username>john</username></user>`))
+	request.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	request.Header.Set("Accept", "application/json;q=0.5, application/xml")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	suite.Equal(http.StatusCreated, recorder.Code)
+	suite.Equal("application/xml", recorder.Header().Get("Content-Type"))
+	suite.Equal(`<user><username>JOHN</username><request_id></request_id></user>`, recorder.Body.String())
+
+	request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`<user><username>john</username></user>`))
+	request.Header.Set("Content-Type", "application/xml")
+	request.Header.Set("Accept", "*/*")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	suite.Equal(http.StatusCreated, recorder.Code)
+	suite.Equal("application/json", recorder.Header().Get("Content-Type"))
+}
+
+func (suite *HttpxTestSuite) TestHeaderMapping() {
+	handler := httpx.Handler[any, httpxTestModel](
+		httpxTestPlan(),
+		nil,
+		nil,
+		nil,
+		httpx.WithRequestHeaders(func(header http.Header, model httpxTestModel) httpxTestModel {
+			model.RequestID = header.Get("X-Request-ID")
+			return model
+		}),
+		httpx.WithResponseHeaders(func(header http.Header, model httpxTestModel) {
+			header.Set("X-Request-ID", model.RequestID)
+		}),
+	)
+
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"username":"john"}`))
+	request.Header.Set("X-Request-ID", "abc")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.Equal("abc", recorder.Header().Get("X-Request-ID"))
+	suite.JSONEq(`{"username":"JOHN","request_id":"abc"}`, recorder.Body.String())
+}
+
+func (suite *HttpxTestSuite) TestEncodeProblem() {
+	handler := httpx.Handler[any, httpxTestModel](httpxTestPlan(), nil, nil, nil, httpx.WithErrorEncoder[httpxTestModel](httpx.EncodeProblem))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{}`)))
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+	suite.Equal(speedrail.ProblemContentType, recorder.Header().Get("Content-Type"))
+
+	var problem speedrail.Problem
+	suite.NoError(json.Unmarshal(recorder.Body.Bytes(), &problem))
+	suite.Equal("about:blank", problem.Type)
+	suite.Equal("Bad Request", problem.Title)
+	suite.Equal(http.StatusBadRequest, problem.Status)
+	suite.Equal("missing username", problem.Detail)
+	suite.Equal("/users", problem.Instance)
+	suite.Nil(problem.Errors)
+
+	handler = httpx.Handler[any, httpxTestModel](httpxTestPlan(), nil, nil, nil, httpx.WithErrorEncoder[httpxTestModel](httpx.EncodeProblem), httpx.WithTrail[httpxTestModel](true))
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{}`)))
+	problem = speedrail.Problem{}
+	suite.NoError(json.Unmarshal(recorder.Body.Bytes(), &problem))
+	suite.Require().Len(problem.Errors, 1)
+	suite.Equal("missing username", problem.Errors[0].Error)
+}
+
+func (suite *HttpxTestSuite) TestRedaction() {
+	plan := speedrail.Plan(
+		func(ctx context.Context, container any, model httpxTestModel) (context.Context, httpxTestModel, speedrail.Error) {
+			return ctx, model, speedrail.NewError(errors.New("dial tcp db.internal:5432: connection refused"), http.StatusServiceUnavailable, "database db.internal is down")
+		},
+	)
+
+	recorder := httptest.NewRecorder()
+	httpx.Handler[any, httpxTestModel](plan, nil, nil, nil).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`)))
+	suite.Equal(http.StatusServiceUnavailable, recorder.Code)
+	suite.JSONEq(`{"error":"Service Unavailable"}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	httpx.Handler[any, httpxTestModel](plan, nil, nil, nil, httpx.WithRedaction[httpxTestModel](nil)).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`)))
+	suite.JSONEq(`{"error":"database db.internal is down"}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	httpx.Handler[any, httpxTestModel](plan, nil, nil, nil, httpx.WithTrail[httpxTestModel](true)).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`)))
+	suite.Contains(recorder.Body.String(), `"error":"database db.internal is down"`)
+	suite.Contains(recorder.Body.String(), "connection refused")
+}
+
+func (suite *HttpxTestSuite) TestTranslator() {
+	catalog := speedrail.NewCatalog("en")
+	suite.Require().NoError(catalog.Add("sv", map[string]string{"username.missing": "användarnamn saknas"}))
+
+	plan := speedrail.Plan(
+		func(ctx context.Context, container any, model httpxTestModel) (context.Context, httpxTestModel, speedrail.Error) {
+			suite.Equal([]string{"sv-se", "en"}, speedrail.Locales(ctx))
+			return ctx, model, speedrail.NewError(nil, http.StatusBadRequest, "missing username", speedrail.WithMessageKey("username.missing", nil))
+		},
+	)
+
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+	request.Header.Set("Accept-Language", "en;q=0.5, sv-SE, *;q=0.1")
+	recorder := httptest.NewRecorder()
+	httpx.Handler[any, httpxTestModel](plan, nil, nil, nil, httpx.WithTranslator[httpxTestModel](catalog)).ServeHTTP(recorder, request)
+	suite.Equal(http.StatusBadRequest, recorder.Code)
+	suite.JSONEq(`{"error":"användarnamn saknas"}`, recorder.Body.String())
+}
+
+func (suite *HttpxTestSuite) TestWarnings() {
+	plan := speedrail.Plan(
+		func(ctx context.Context, container any, model httpxTestModel) (context.Context, httpxTestModel, speedrail.Error) {
+			speedrail.Warn(ctx, speedrail.Warning{Code: "SHORT_USERNAME", Field: "username", Message: "username is short"})
+			return ctx, model, nil
+		},
+	)
+
+	recorder := httptest.NewRecorder()
+	httpx.Handler[any, httpxTestModel](plan, nil, httpx.EncodeJSONWithWarnings[httpxTestModel], nil).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"username":"jo"}`)))
+	suite.Equal(http.StatusOK, recorder.Code)
+	suite.JSONEq(`{"data":{"username":"jo","request_id":""},"warnings":[{"code":"SHORT_USERNAME","field":"username","message":"username is short"}]}`, recorder.Body.String())
+}
+
+func TestHttpxTestSuite(t *testing.T) {
+	suite.Run(t, new(HttpxTestSuite))
+}
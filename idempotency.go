@@ -0,0 +1,227 @@
+package speedrail
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrIdempotencyKeyInFlight is the error returned when a plan with the same idempotency key is already executing.
+var ErrIdempotencyKeyInFlight = errors.New("plan with idempotency key is already executing")
+
+// ErrIdempotencyStore is the error returned when the idempotency store fails.
+var ErrIdempotencyStore = errors.New("idempotency store failed")
+
+// ErrIdempotencyKeyMissing is the error returned when a plan is executed with an empty idempotency key.
+var ErrIdempotencyKeyMissing = errors.New("idempotency key is missing")
+
+// ErrIdempotencyKeyReused is the error returned when an idempotency key is reused with a different model.
+var ErrIdempotencyKeyReused = errors.New("idempotency key is reused with a different model")
+
+// DefaultIdempotencyLease is how long ExecuteIdempotent reserves a key, unless another lease is given with
+// WithIdempotencyLease.
+const DefaultIdempotencyLease = time.Minute
+
+// IdempotencyRecord is the state of a plan executed with an idempotency key.
+type IdempotencyRecord[M any] struct {
+	// Completed is true when the plan has finished executing, and Model and Err hold its result.
+	Completed bool
+	// Fingerprint identifies the model that the plan was executed with, see Fingerprint.
+	Fingerprint string
+	// ReservedAt is when the key was reserved, and Lease how long the reservation lasts if the plan is not completed.
+	ReservedAt time.Time
+	Lease      time.Duration
+	Model      M
+	Err        Error
+}
+
+// expired reports if the record is a reservation whose lease has run out, such as when the process executing the plan
+// crashed, so that the key can be reserved again.
+func (r IdempotencyRecord[M]) expired(now time.Time) bool {
+	return !r.Completed && r.Lease > 0 && !now.Before(r.ReservedAt.Add(r.Lease))
+}
+
+// IdempotencyStore keeps track of plans executed with an idempotency key.
+type IdempotencyStore[M any] interface {
+	// Reserve will reserve key for execution of a model with fingerprint for lease and return true. If key is already
+	// reserved, or completed, the stored record, with the fingerprint it was reserved with, is returned and false. A
+	// reservation whose lease has run out is replaced, and a lease of zero or less never runs out.
+	Reserve(ctx context.Context, key string, fingerprint string, lease time.Duration) (IdempotencyRecord[M], bool, error)
+	// Complete stores the result of the execution for a reserved key.
+	Complete(ctx context.Context, key string, record IdempotencyRecord[M]) error
+	// Release removes the reservation of key without storing a result, so that the plan can be executed again.
+	Release(ctx context.Context, key string) error
+}
+
+// memoryIdempotencyRecord is a record in the MemoryIdempotencyStore.
+type memoryIdempotencyRecord[M any] struct {
+	record    IdempotencyRecord[M]
+	expiresAt time.Time
+}
+
+// MemoryIdempotencyStore is an in-memory IdempotencyStore.
+type MemoryIdempotencyStore[M any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	records map[string]memoryIdempotencyRecord[M]
+}
+
+// Type check that MemoryIdempotencyStore implements IdempotencyStore interface
+var _ IdempotencyStore[any] = &MemoryIdempotencyStore[any]{}
+
+// NewMemoryIdempotencyStore will return an in-memory idempotency store where completed records are kept for ttl. A ttl
+// of zero or less means that completed records are kept forever.
+func NewMemoryIdempotencyStore[M any](ttl time.Duration) IdempotencyStore[M] {
+	return &MemoryIdempotencyStore[M]{
+		ttl:     ttl,
+		records: map[string]memoryIdempotencyRecord[M]{},
+	}
+}
+
+// Reserve will reserve key for execution of a model with fingerprint for lease and return true. If key is already
+// reserved, or completed, the stored record, with the fingerprint it was reserved with, is returned and false. A
+// reservation whose lease has run out is replaced, and a lease of zero or less never runs out.
+func (s *MemoryIdempotencyStore[M]) Reserve(_ context.Context, key string, fingerprint string, lease time.Duration) (IdempotencyRecord[M], bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	current, ok := s.records[key]
+	if ok && (current.expiresAt.IsZero() || now.Before(current.expiresAt)) && !current.record.expired(now) {
+		return current.record, false, nil
+	}
+
+	record := IdempotencyRecord[M]{Fingerprint: fingerprint, ReservedAt: now, Lease: lease}
+	s.records[key] = memoryIdempotencyRecord[M]{record: record}
+	return record, true, nil
+}
+
+// Complete stores the result of the execution for a reserved key.
+func (s *MemoryIdempotencyStore[M]) Complete(_ context.Context, key string, record IdempotencyRecord[M]) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record.Completed = true
+	stored := memoryIdempotencyRecord[M]{record: record}
+	if s.ttl > 0 {
+		stored.expiresAt = time.Now().Add(s.ttl)
+	}
+
+	s.records[key] = stored
+	return nil
+}
+
+// Release removes the reservation of key without storing a result, so that the plan can be executed again.
+func (s *MemoryIdempotencyStore[M]) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// idempotencyConfig holds the settings of ExecuteIdempotent.
+type idempotencyConfig struct {
+	pollInterval time.Duration
+	lease        time.Duration
+}
+
+// IdempotencyOption configures the behaviour of ExecuteIdempotent.
+type IdempotencyOption func(*idempotencyConfig)
+
+// WaitInFlight will make ExecuteIdempotent wait for an in flight execution with the same key to complete, instead of
+// rejecting it. The store is polled at the given interval until the execution has completed or ctx is done.
+func WaitInFlight(pollInterval time.Duration) IdempotencyOption {
+	return func(config *idempotencyConfig) {
+		config.pollInterval = pollInterval
+	}
+}
+
+// WithIdempotencyLease will make ExecuteIdempotent reserve the key for lease instead of DefaultIdempotencyLease. If the
+// plan has not completed when the lease runs out, such as when the process crashed, the key can be reserved again, so
+// the lease should be longer than the plan takes to execute. A lease of zero or less never runs out.
+func WithIdempotencyLease(lease time.Duration) IdempotencyOption {
+	return func(config *idempotencyConfig) {
+		config.lease = lease
+	}
+}
+
+// Fingerprint will return a fingerprint of model, which is the SHA-256 hash of its JSON encoding, or of its Go syntax
+// representation if it cannot be encoded as JSON.
+func Fingerprint(model any) string {
+	data, err := json.Marshal(model)
+	if err != nil {
+		data = []byte(fmt.Sprintf("%#v", model))
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ExecuteIdempotent executes the plan once per idempotency key. If a plan with the same key has already completed, the
+// stored model and error are returned without executing any strategies. If a plan with the same key is executing an
+// error with status code 409 is returned, unless WaitInFlight is given. The key is stored with the Fingerprint of the
+// model, and a key that is reused with a different model is rejected with status code 422. An empty key is rejected with
+// status code 400, use Execute if the key is optional. The key is reserved for DefaultIdempotencyLease while the plan
+// executes, unless another lease is given with WithIdempotencyLease.
+func (s Speedrail[C, M]) ExecuteIdempotent(ctx context.Context, store IdempotencyStore[M], key string, container C, model M, options ...IdempotencyOption) (context.Context, M, Error) {
+	config := idempotencyConfig{lease: DefaultIdempotencyLease}
+	for _, option := range options {
+		option(&config)
+	}
+
+	if key == "" {
+		return ctx, model, NewError(ErrIdempotencyKeyMissing, http.StatusBadRequest, "idempotency key is missing")
+	}
+
+	fingerprint := Fingerprint(model)
+	for {
+		record, reserved, err := store.Reserve(ctx, key, fingerprint, config.lease)
+		if err != nil {
+			return ctx, model, NewError(errors.Join(ErrIdempotencyStore, err), http.StatusInternalServerError, "idempotency store failed")
+		}
+
+		if reserved {
+			break
+		}
+
+		if record.Fingerprint != fingerprint {
+			return ctx, model, NewError(ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, "idempotency key is reused with a different request")
+		}
+
+		if record.Completed {
+			return ctx, record.Model, record.Err
+		}
+
+		if config.pollInterval <= 0 {
+			return ctx, model, NewError(ErrIdempotencyKeyInFlight, http.StatusConflict, "request with idempotency key is already in progress")
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx, model, NewError(ctx.Err(), http.StatusConflict, "request with idempotency key is already in progress")
+		case <-time.After(config.pollInterval):
+		}
+	}
+
+	completed := false
+	defer func() {
+		if !completed {
+			_ = store.Release(ctx, key)
+		}
+	}()
+
+	resultCtx, model, resultErr := s.Execute(ctx, container, model)
+	if err := store.Complete(ctx, key, IdempotencyRecord[M]{Fingerprint: fingerprint, Model: model, Err: resultErr}); err != nil {
+		return resultCtx, model, NewError(errors.Join(ErrIdempotencyStore, err), http.StatusInternalServerError, "idempotency store failed")
+	}
+
+	completed = true
+	return resultCtx, model, resultErr
+}
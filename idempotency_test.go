@@ -0,0 +1,174 @@
+package speedrail_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Kansuler/speedrail"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type SpeedrailIdempotencyTestSuite struct {
+	suite.Suite
+}
+
+type idempotencyTestModel struct {
+	Amount  int
+	Charged int32
+}
+
+func (suite *SpeedrailIdempotencyTestSuite) TestExecuteIdempotent() {
+	var charges int32
+	plan := speedrail.Plan(
+		func(ctx context.Context, container any, model idempotencyTestModel) (context.Context, idempotencyTestModel, speedrail.Error) {
+			model.Charged = atomic.AddInt32(&charges, 1)
+			return ctx, model, nil
+		},
+	)
+
+	store := speedrail.NewMemoryIdempotencyStore[idempotencyTestModel](time.Minute)
+	_, model, err := plan.ExecuteIdempotent(context.Background(), store, "key-1", nil, idempotencyTestModel{Amount: 100})
+	suite.NoError(err)
+	suite.Equal(int32(1), model.Charged)
+
+	_, model, err = plan.ExecuteIdempotent(context.Background(), store, "key-1", nil, idempotencyTestModel{Amount: 100})
+	suite.NoError(err)
+	suite.Equal(int32(1), model.Charged)
+	suite.Equal(int32(1), atomic.LoadInt32(&charges))
+
+	_, model, err = plan.ExecuteIdempotent(context.Background(), store, "key-2", nil, idempotencyTestModel{Amount: 100})
+	suite.NoError(err)
+	suite.Equal(int32(2), model.Charged)
+}
+
+func (suite *SpeedrailIdempotencyTestSuite) TestStoredError() {
+	var calls int32
+	plan := speedrail.Plan(
+		func(ctx context.Context, container any, model idempotencyTestModel) (context.Context, idempotencyTestModel, speedrail.Error) {
+			atomic.AddInt32(&calls, 1)
+			return ctx, model, speedrail.NewError(errors.New("declined"), http.StatusPaymentRequired, "card declined")
+		},
+	)
+
+	store := speedrail.NewMemoryIdempotencyStore[idempotencyTestModel](0)
+	for i := 0; i < 2; i++ {
+		_, _, err := plan.ExecuteIdempotent(context.Background(), store, "key", nil, idempotencyTestModel{})
+		suite.Error(err)
+		suite.Equal(http.StatusPaymentRequired, err.StatusCode())
+		suite.Equal("card declined", err.Error())
+	}
+	suite.Equal(int32(1), atomic.LoadInt32(&calls))
+}
+
+func (suite *SpeedrailIdempotencyTestSuite) TestInFlight() {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	plan := speedrail.Plan(
+		func(ctx context.Context, container any, model idempotencyTestModel) (context.Context, idempotencyTestModel, speedrail.Error) {
+			close(started)
+			<-release
+			model.Charged = 1
+			return ctx, model, nil
+		},
+	)
+
+	store := speedrail.NewMemoryIdempotencyStore[idempotencyTestModel](0)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _, _ = plan.ExecuteIdempotent(context.Background(), store, "key", nil, idempotencyTestModel{})
+	}()
+	<-started
+
+	_, _, err := plan.ExecuteIdempotent(context.Background(), store, "key", nil, idempotencyTestModel{})
+	suite.Error(err)
+	suite.Equal(http.StatusConflict, err.StatusCode())
+	suite.ErrorIs(err, speedrail.ErrIdempotencyKeyInFlight)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+
+	_, model, err := plan.ExecuteIdempotent(context.Background(), store, "key", nil, idempotencyTestModel{}, speedrail.WaitInFlight(5*time.Millisecond))
+	suite.NoError(err)
+	suite.Equal(int32(1), model.Charged)
+	<-done
+}
+
+func (suite *SpeedrailIdempotencyTestSuite) TestMissingKey() {
+	var calls int32
+	plan := speedrail.Plan(
+		func(ctx context.Context, container any, model idempotencyTestModel) (context.Context, idempotencyTestModel, speedrail.Error) {
+			atomic.AddInt32(&calls, 1)
+			return ctx, model, nil
+		},
+	)
+
+	store := speedrail.NewMemoryIdempotencyStore[idempotencyTestModel](0)
+	_, _, err := plan.ExecuteIdempotent(context.Background(), store, "", nil, idempotencyTestModel{Amount: 100})
+	suite.ErrorIs(err, speedrail.ErrIdempotencyKeyMissing)
+	suite.Equal(http.StatusBadRequest, err.StatusCode())
+	suite.Equal(int32(0), atomic.LoadInt32(&calls))
+}
+
+func (suite *SpeedrailIdempotencyTestSuite) TestReusedKey() {
+	var calls int32
+	plan := speedrail.Plan(
+		func(ctx context.Context, container any, model idempotencyTestModel) (context.Context, idempotencyTestModel, speedrail.Error) {
+			model.Charged = atomic.AddInt32(&calls, 1)
+			return ctx, model, nil
+		},
+	)
+
+	store := speedrail.NewMemoryIdempotencyStore[idempotencyTestModel](0)
+	_, _, err := plan.ExecuteIdempotent(context.Background(), store, "key", nil, idempotencyTestModel{Amount: 100})
+	suite.NoError(err)
+
+	_, model, err := plan.ExecuteIdempotent(context.Background(), store, "key", nil, idempotencyTestModel{Amount: 200})
+	suite.ErrorIs(err, speedrail.ErrIdempotencyKeyReused)
+	suite.Equal(http.StatusUnprocessableEntity, err.StatusCode())
+	suite.Equal(idempotencyTestModel{Amount: 200}, model)
+	suite.Equal(int32(1), atomic.LoadInt32(&calls))
+}
+
+func (suite *SpeedrailIdempotencyTestSuite) TestLease() {
+	store := speedrail.NewMemoryIdempotencyStore[idempotencyTestModel](0)
+	fingerprint := speedrail.Fingerprint(idempotencyTestModel{Amount: 100})
+
+	// A reservation that is never completed, as if the process crashed while executing the plan.
+	record, reserved, err := store.Reserve(context.Background(), "key", fingerprint, 10*time.Millisecond)
+	suite.NoError(err)
+	suite.True(reserved)
+	suite.False(record.ReservedAt.IsZero())
+
+	plan := speedrail.Plan(
+		func(ctx context.Context, container any, model idempotencyTestModel) (context.Context, idempotencyTestModel, speedrail.Error) {
+			model.Charged = 1
+			return ctx, model, nil
+		},
+	)
+
+	_, _, err = plan.ExecuteIdempotent(context.Background(), store, "key", nil, idempotencyTestModel{Amount: 100})
+	suite.ErrorIs(err, speedrail.ErrIdempotencyKeyInFlight)
+
+	time.Sleep(20 * time.Millisecond)
+	_, model, err := plan.ExecuteIdempotent(context.Background(), store, "key", nil, idempotencyTestModel{Amount: 100}, speedrail.WithIdempotencyLease(0))
+	suite.NoError(err)
+	suite.Equal(int32(1), model.Charged)
+
+	_, reserved, err = store.Reserve(context.Background(), "other", fingerprint, 0)
+	suite.NoError(err)
+	suite.True(reserved)
+	time.Sleep(20 * time.Millisecond)
+	_, reserved, err = store.Reserve(context.Background(), "other", fingerprint, 0)
+	suite.NoError(err)
+	suite.False(reserved)
+}
+
+func TestSpeedrailIdempotencyTestSuite(t *testing.T) {
+	suite.Run(t, new(SpeedrailIdempotencyTestSuite))
+}
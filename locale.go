@@ -0,0 +1,132 @@
+package speedrail
+
+import (
+	"context"
+)
+
+// Translator translates the outgoing message with key to locale, with args as the arguments of its template. It returns
+// false if there is no translation. An empty locale asks for the translation in the default locale of the translator.
+type Translator interface {
+	Translate(locale, key string, args map[string]any) (string, bool)
+}
+
+// localeKey is the context key of the preferred locales.
+type localeKey struct{}
+
+// WithLocale will return a context with the preferred locales, such as sv-SE and en, in order of preference.
+func WithLocale(ctx context.Context, locales ...string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locales)
+}
+
+// Locales returns the preferred locales of the context, in order of preference.
+func Locales(ctx context.Context) []string {
+	locales, _ := ctx.Value(localeKey{}).([]string)
+	return locales
+}
+
+// Localizer is implemented by errors that wrap another error, to keep the wrapper when the error is localized.
+type Localizer interface {
+	Localize(context.Context, Translator) Error
+}
+
+// Localize will return err with the outgoing messages of the trail translated to the preferred locales of the context.
+// Entries without a message key, or without a translation, keep their outgoing message. The outgoing message of the
+// error keeps the messages that its merge policy picked, each replaced with its translation, and is kept as it is if it
+// can not be matched to the trail. Errors that implement Localizer are localized by themselves.
+func Localize(ctx context.Context, translator Translator, err Error) Error {
+	if err == nil || translator == nil {
+		return err
+	}
+
+	if localizer, ok := err.(Localizer); ok {
+		return localizer.Localize(ctx, translator)
+	}
+
+	trail, outgoingMessage, translated := localizeTrail(ctx, translator, err.Trail(), err.Error())
+	if !translated {
+		return err
+	}
+
+	return ErrorFromTrail(trail, err.StatusCode(), outgoingMessage)
+}
+
+// Localize will return the error with the outgoing messages of the trail translated to the preferred locales of the
+// context.
+func (e defaultError) Localize(ctx context.Context, translator Translator) Error {
+	trail, outgoingMessage, translated := localizeTrail(ctx, translator, e.trail, e.outgoingMessage)
+	if !translated {
+		return e
+	}
+
+	e.trail = trail
+	e.outgoingMessage = outgoingMessage
+	return e
+}
+
+// localizeTrail returns a copy of trail with the outgoing messages translated, and outgoingMessage rebuilt from the
+// translations. It returns false if no entry was translated.
+func localizeTrail(ctx context.Context, translator Translator, trail Trail, outgoingMessage string) (Trail, string, bool) {
+	translated := false
+	localized := make(Trail, 0, len(trail))
+	for _, entry := range trail {
+		if entry.MessageKey != "" {
+			if message, ok := translate(translator, Locales(ctx), entry.MessageKey, entry.MessageArgs); ok {
+				entry.Message = message
+				translated = true
+			}
+		}
+
+		localized = append(localized, entry)
+	}
+
+	if !translated {
+		return trail, outgoingMessage, false
+	}
+
+	return localized, localizeMessage(ctx, trail, localized, outgoingMessage), true
+}
+
+// localizeMessage rebuilds outgoingMessage from the translated trail. If the message is the messages of the trail
+// joined with the merge policy of the context, or with DefaultMergePolicy, the translations are joined the same way. If
+// it is the message of a single entry, such as with FirstMessage, it is replaced with the translation of that entry.
+// Otherwise the message was shaped by something else than the trail, and is kept as it is.
+func localizeMessage(ctx context.Context, trail, localized Trail, outgoingMessage string) string {
+	for _, join := range []func(string, string) string{mergePolicy(ctx).Message, DefaultMergePolicy.Message} {
+		if join != nil && joinMessages(trail, join) == outgoingMessage {
+			return joinMessages(localized, join)
+		}
+	}
+
+	for index, entry := range trail {
+		if entry.Message == outgoingMessage {
+			return localized[index].Message
+		}
+	}
+
+	return outgoingMessage
+}
+
+// joinMessages joins the outgoing messages of the trail with join, in order.
+func joinMessages(trail Trail, join func(string, string) string) string {
+	var message string
+	for index, entry := range trail {
+		if index == 0 {
+			message = entry.Message
+		} else {
+			message = join(message, entry.Message)
+		}
+	}
+
+	return message
+}
+
+// translate tries the locales in order, and then the default locale of the translator.
+func translate(translator Translator, locales []string, key string, args map[string]any) (string, bool) {
+	for _, locale := range locales {
+		if message, ok := translator.Translate(locale, key, args); ok {
+			return message, true
+		}
+	}
+
+	return translator.Translate("", key, args)
+}
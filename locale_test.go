@@ -0,0 +1,96 @@
+package speedrail_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Kansuler/speedrail"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"testing"
+	"testing/fstest"
+)
+
+type SpeedrailLocaleTestSuite struct {
+	suite.Suite
+}
+
+func localeTestCatalog() fstest.MapFS {
+	return fstest.MapFS{
+		"locales/en.json": {Data: []byte(`{"user.exists":"User {{.email}} already exists","password.short":"Password must be at least {{.min}} characters"}`)},
+		"locales/sv.json": {Data: []byte(`{"user.exists":"Användaren {{.email}} finns redan"}`)},
+		"locales/README":  {Data: []byte(`not a catalog`)},
+	}
+}
+
+func (suite *SpeedrailLocaleTestSuite) TestCatalog() {
+	catalog, err := speedrail.LoadCatalog(localeTestCatalog(), "locales/*.json", "en")
+	suite.Require().NoError(err)
+
+	message, ok := catalog.Translate("sv", "user.exists", map[string]any{"email": "john@example.com"})
+	suite.True(ok)
+	suite.Equal("Användaren john@example.com finns redan", message)
+
+	message, ok = catalog.Translate("sv_SE", "user.exists", map[string]any{"email": "john@example.com"})
+	suite.True(ok)
+	suite.Equal("Användaren john@example.com finns redan", message)
+
+	_, ok = catalog.Translate("sv", "password.short", nil)
+	suite.False(ok)
+
+	message, ok = catalog.Translate("", "password.short", map[string]any{"min": 8})
+	suite.True(ok)
+	suite.Equal("Password must be at least 8 characters", message)
+
+	_, ok = catalog.Translate("sv", "user.exists", map[string]any{})
+	suite.False(ok)
+
+	_, err = speedrail.LoadCatalog(fstest.MapFS{"sv.json": {Data: []byte(`{"broken":"{{.email"}`)}}, "*.json", "sv")
+	suite.Error(err)
+
+	_, err = speedrail.LoadCatalog(fstest.MapFS{"sv.json": {Data: []byte(`[]`)}}, "*.json", "sv")
+	suite.Error(err)
+}
+
+func (suite *SpeedrailLocaleTestSuite) TestLocalize() {
+	catalog, err := speedrail.LoadCatalog(localeTestCatalog(), "locales/*.json", "en")
+	suite.Require().NoError(err)
+
+	speedrailErr := speedrail.NewError(errors.New("duplicate key"), http.StatusConflict, "user already exists",
+		speedrail.WithMessageKey("user.exists", map[string]any{"email": "john@example.com"}),
+	).Merge(speedrail.NewError(nil, http.StatusBadRequest, "password too short",
+		speedrail.WithMessageKey("password.short", map[string]any{"min": 8}),
+	)).Merge(speedrail.NewError(nil, http.StatusBadRequest, "no translation"))
+
+	ctx := speedrail.WithLocale(context.Background(), "sv-SE", "en")
+	suite.Equal([]string{"sv-SE", "en"}, speedrail.Locales(ctx))
+
+	localized := speedrail.Localize(ctx, catalog, speedrailErr)
+	suite.Equal(http.StatusConflict, localized.StatusCode())
+	suite.Equal("Användaren john@example.com finns redan; Password must be at least 8 characters; no translation", localized.Error())
+	suite.Equal("Användaren john@example.com finns redan", localized.Trail()[0].Message)
+	suite.True(errors.Is(localized, speedrailErr.Trail()[0].Error))
+
+	localized = speedrail.Localize(speedrail.WithMergePolicy(ctx, speedrail.MergePolicy{Message: speedrail.FirstMessage}), catalog, speedrailErr)
+	suite.Equal("Användaren john@example.com finns redan; Password must be at least 8 characters; no translation", localized.Error())
+
+	first := speedrail.MergeErrors(speedrail.MergePolicy{Message: speedrail.FirstMessage},
+		speedrail.NewError(nil, http.StatusConflict, "user already exists", speedrail.WithMessageKey("user.exists", map[string]any{"email": "john@example.com"})),
+		speedrail.NewError(nil, http.StatusBadRequest, "no translation"),
+	)
+	suite.Equal("Användaren john@example.com finns redan", speedrail.Localize(ctx, catalog, first).Error())
+
+	joined := speedrail.ErrorFromTrail(speedrailErr.Trail(), http.StatusConflict, "something else")
+	suite.Equal("something else", speedrail.Localize(ctx, catalog, joined).Error())
+
+	localized = speedrail.Localize(context.Background(), catalog, speedrailErr)
+	suite.Equal("User john@example.com already exists; Password must be at least 8 characters; no translation", localized.Error())
+
+	untranslated := speedrail.NewError(nil, http.StatusBadRequest, "no translation")
+	suite.Equal(untranslated, speedrail.Localize(ctx, catalog, untranslated))
+	suite.Equal(speedrailErr, speedrail.Localize(ctx, nil, speedrailErr))
+	suite.Nil(speedrail.Localize(ctx, catalog, nil))
+}
+
+func TestSpeedrailLocaleTestSuite(t *testing.T) {
+	suite.Run(t, new(SpeedrailLocaleTestSuite))
+}
@@ -0,0 +1,130 @@
+package speedrail
+
+import (
+	"context"
+	"strings"
+)
+
+// StatusCodePolicy decides the status code of two merged errors, given the status code of the current error and the
+// error that is merged into it.
+type StatusCodePolicy func(current, next int) int
+
+// MaxStatusCode keeps the numerically highest status code.
+func MaxStatusCode(current, next int) int {
+	if next > current {
+		return next
+	}
+
+	return current
+}
+
+// FirstStatusCode keeps the status code of the first error.
+func FirstStatusCode(current, _ int) int {
+	return current
+}
+
+// LastStatusCode keeps the status code of the last error.
+func LastStatusCode(_, next int) int {
+	return next
+}
+
+// PreferServerErrors keeps the first 5xx status code, and otherwise the highest status code.
+func PreferServerErrors(current, next int) int {
+	return preferClass(5, current, next)
+}
+
+// PreferClientErrors keeps the first 4xx status code, and otherwise the highest status code.
+func PreferClientErrors(current, next int) int {
+	return preferClass(4, current, next)
+}
+
+// preferClass keeps the first status code of the given class, such as 4 for 4xx, and otherwise the highest status code.
+func preferClass(class, current, next int) int {
+	switch {
+	case current/100 == class:
+		return current
+	case next/100 == class:
+		return next
+	}
+
+	return MaxStatusCode(current, next)
+}
+
+// MessageJoiner decides the outgoing message of two merged errors, given the message of the current error and the error
+// that is merged into it.
+type MessageJoiner func(current, next string) string
+
+// JoinMessages joins the outgoing messages with separator.
+func JoinMessages(separator string) MessageJoiner {
+	return func(current, next string) string {
+		return strings.Join([]string{current, next}, separator)
+	}
+}
+
+// FirstMessage keeps the outgoing message of the first error.
+func FirstMessage(current, _ string) string {
+	return current
+}
+
+// LastMessage keeps the outgoing message of the last error.
+func LastMessage(_, next string) string {
+	return next
+}
+
+// MergePolicy decides how errors are merged. The trails of the errors are always concatenated.
+type MergePolicy struct {
+	StatusCode StatusCodePolicy
+	Message    MessageJoiner
+}
+
+// DefaultMergePolicy is the policy used by Error.Merge, it keeps the highest status code and joins the outgoing
+// messages with a semicolon.
+var DefaultMergePolicy = MergePolicy{
+	StatusCode: MaxStatusCode,
+	Message:    JoinMessages("; "),
+}
+
+// PolicyMerger is implemented by errors that can be merged with a MergePolicy.
+type PolicyMerger interface {
+	MergeWithPolicy(Error, MergePolicy) Error
+}
+
+// MergeErrors will merge next into current with policy. Errors that do not implement PolicyMerger are merged with their
+// Merge method. A nil current error results in next.
+func MergeErrors(policy MergePolicy, current, next Error) Error {
+	if current == nil {
+		return next
+	}
+
+	if next == nil {
+		return current
+	}
+
+	if merger, ok := current.(PolicyMerger); ok {
+		return merger.MergeWithPolicy(next, policy)
+	}
+
+	return current.Merge(next)
+}
+
+// mergePolicyKey is the context key of the merge policy.
+type mergePolicyKey struct{}
+
+// WithMergePolicy will return a context that makes Merge, and other combinators that merge errors, use policy. Execute a
+// plan with the context to set the policy for the whole plan.
+func WithMergePolicy(ctx context.Context, policy MergePolicy) context.Context {
+	return context.WithValue(ctx, mergePolicyKey{}, policy)
+}
+
+// mergePolicy returns the merge policy of the context, or DefaultMergePolicy.
+func mergePolicy(ctx context.Context) MergePolicy {
+	if ctx == nil {
+		return DefaultMergePolicy
+	}
+
+	if policy, ok := ctx.Value(mergePolicyKey{}).(MergePolicy); ok {
+		return policy
+	}
+
+	return DefaultMergePolicy
+}
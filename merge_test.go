@@ -0,0 +1,86 @@
+package speedrail_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Kansuler/speedrail"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"testing"
+)
+
+type SpeedrailMergeTestSuite struct {
+	suite.Suite
+}
+
+func mergeTestError(statusCode int) speedrail.Strategy[any, any] {
+	return func(ctx context.Context, container any, model any) (context.Context, any, speedrail.Error) {
+		message := http.StatusText(statusCode)
+		return ctx, model, speedrail.NewError(errors.New(message), statusCode, message)
+	}
+}
+
+func (suite *SpeedrailMergeTestSuite) TestStatusCodePolicies() {
+	suite.Equal(http.StatusUnprocessableEntity, speedrail.MaxStatusCode(http.StatusNotFound, http.StatusUnprocessableEntity))
+	suite.Equal(http.StatusNotFound, speedrail.FirstStatusCode(http.StatusNotFound, http.StatusUnprocessableEntity))
+	suite.Equal(http.StatusUnprocessableEntity, speedrail.LastStatusCode(http.StatusNotFound, http.StatusUnprocessableEntity))
+	suite.Equal(http.StatusBadRequest, speedrail.PreferClientErrors(http.StatusBadRequest, http.StatusServiceUnavailable))
+	suite.Equal(http.StatusBadRequest, speedrail.PreferClientErrors(http.StatusServiceUnavailable, http.StatusBadRequest))
+	suite.Equal(http.StatusNotFound, speedrail.PreferClientErrors(http.StatusNotFound, http.StatusUnprocessableEntity))
+	suite.Equal(http.StatusServiceUnavailable, speedrail.PreferServerErrors(http.StatusBadRequest, http.StatusServiceUnavailable))
+	suite.Equal(http.StatusUnprocessableEntity, speedrail.PreferServerErrors(http.StatusNotFound, http.StatusUnprocessableEntity))
+}
+
+func (suite *SpeedrailMergeTestSuite) TestMergeUsing() {
+	plan := speedrail.Plan(
+		speedrail.MergeUsing(
+			speedrail.MergePolicy{StatusCode: speedrail.PreferClientErrors, Message: speedrail.JoinMessages(", ")},
+			mergeTestError(http.StatusServiceUnavailable),
+			mergeTestError(http.StatusNotFound),
+			mergeTestError(http.StatusUnprocessableEntity),
+		),
+	)
+
+	_, _, err := plan.Execute(context.Background(), nil, nil)
+	suite.Error(err)
+	suite.Equal(http.StatusNotFound, err.StatusCode())
+	suite.Equal("Service Unavailable, Not Found, Unprocessable Entity", err.Error())
+	suite.Equal(3, len(err.Trail()))
+}
+
+func (suite *SpeedrailMergeTestSuite) TestNilContext() {
+	ctx, _, err := speedrail.Merge(mergeTestError(http.StatusNotFound), mergeTestError(http.StatusUnprocessableEntity))(nil, nil, nil)
+	suite.Nil(ctx)
+	suite.Equal(http.StatusUnprocessableEntity, err.StatusCode())
+	suite.Equal("Not Found; Unprocessable Entity", err.Error())
+}
+
+func (suite *SpeedrailMergeTestSuite) TestWithMergePolicy() {
+	plan := speedrail.Plan(
+		speedrail.Merge(
+			mergeTestError(http.StatusNotFound),
+			mergeTestError(http.StatusUnprocessableEntity),
+		),
+	)
+
+	_, _, err := plan.Execute(context.Background(), nil, nil)
+	suite.Error(err)
+	suite.Equal(http.StatusUnprocessableEntity, err.StatusCode())
+	suite.Equal("Not Found; Unprocessable Entity", err.Error())
+
+	ctx := speedrail.WithMergePolicy(context.Background(), speedrail.MergePolicy{
+		StatusCode: func(current, next int) int {
+			return current
+		},
+		Message: speedrail.FirstMessage,
+	})
+	_, _, err = plan.Execute(ctx, nil, nil)
+	suite.Error(err)
+	suite.Equal(http.StatusNotFound, err.StatusCode())
+	suite.Equal("Not Found", err.Error())
+	suite.Equal(2, len(err.Trail()))
+}
+
+func TestSpeedrailMergeTestSuite(t *testing.T) {
+	suite.Run(t, new(SpeedrailMergeTestSuite))
+}
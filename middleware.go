@@ -0,0 +1,92 @@
+package speedrail
+
+import (
+	"context"
+	"net/http"
+)
+
+// Middleware wraps a strategy, to add behaviour such as logging, authorization checks, timing or recovery around it.
+type Middleware[C, M any] func(Strategy[C, M]) Strategy[C, M]
+
+// middlewareKey is the context key of the middlewares that wrap the strategies nested inside a strategy.
+type middlewareKey[C, M any] struct{}
+
+// Use will return the strategy wrapped with middlewares. Strategies nested inside the strategy by If, IfElse, Merge,
+// Group and the other helper functions are wrapped as well. The first middleware is the outermost.
+func (s Strategy[C, M]) Use(middlewares ...Middleware[C, M]) Strategy[C, M] {
+	problems := append(namedProblems("strategy", s), middlewareProblems(middlewares)...)
+	if len(problems) > 0 {
+		return validated("Use", problems, s)
+	}
+
+	wrapped := chain(middlewares, s)
+	return func(ctx context.Context, container C, model M) (context.Context, M, Error) {
+		if ctx == nil {
+			return wrapped(ctx, container, model)
+		}
+
+		outer := contextMiddlewares[C, M](ctx)
+		ctx = context.WithValue(ctx, middlewareKey[C, M]{}, append(append([]Middleware[C, M](nil), outer...), middlewares...))
+
+		ctx, model, err := wrapped(ctx, container, model)
+		if ctx != nil {
+			// Strategies after this one are not nested inside it, and are only wrapped by the outer middlewares.
+			ctx = context.WithValue(ctx, middlewareKey[C, M]{}, outer)
+		}
+
+		return ctx, model, err
+	}
+}
+
+// Use will return a plan where every strategy, and every strategy nested inside them, is wrapped with middlewares. The
+// first middleware is the outermost.
+func (s Speedrail[C, M]) Use(middlewares ...Middleware[C, M]) Speedrail[C, M] {
+	if s == nil {
+		return nil
+	}
+
+	plan := make(Speedrail[C, M], len(s))
+	for index, strategy := range s {
+		plan[index] = strategy.Use(middlewares...)
+	}
+
+	return plan
+}
+
+// contextMiddlewares returns the middlewares of the context.
+func contextMiddlewares[C, M any](ctx context.Context) []Middleware[C, M] {
+	middlewares, _ := ctx.Value(middlewareKey[C, M]{}).([]Middleware[C, M])
+	return middlewares
+}
+
+// chain wraps strategy with middlewares, so that the first middleware is the outermost.
+func chain[C, M any](middlewares []Middleware[C, M], strategy Strategy[C, M]) Strategy[C, M] {
+	for index := len(middlewares) - 1; index >= 0; index-- {
+		strategy = middlewares[index](strategy)
+	}
+
+	return strategy
+}
+
+// run executes a nested strategy, wrapped with the middlewares of the context. Helper functions execute the strategies
+// they are given with run, so that middlewares given to Use reach every strategy of a plan. A nil strategy fails with
+// ErrInvalidPlan, and a strategy that returns no context fails with ErrNoContextReturned while the execution continues
+// with the context it was given. A nil context has no middlewares, so the strategy is executed as it is.
+func run[C, M any](ctx context.Context, strategy Strategy[C, M], container C, model M) (context.Context, M, Error) {
+	if strategy == nil {
+		return ctx, model, NewError(invalidPlanError{problems: []string{"strategy is nil"}}, http.StatusInternalServerError, "invalid plan")
+	}
+
+	if ctx == nil {
+		return strategy(ctx, container, model)
+	}
+
+	resultCtx, model, err := chain(contextMiddlewares[C, M](ctx), strategy)(ctx, container, model)
+	err = withPlanStack(ctx, err, strategy, 1)
+	if resultCtx == nil {
+		missing := newError(funcFullName(strategy), captureStack(1), ErrNoContextReturned, http.StatusInternalServerError, "no context returned by strategy")
+		return ctx, model, MergeErrors(mergePolicy(ctx), err, missing)
+	}
+
+	return resultCtx, model, err
+}
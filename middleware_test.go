@@ -0,0 +1,107 @@
+package speedrail_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Kansuler/speedrail"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"testing"
+)
+
+type SpeedrailMiddlewareTestSuite struct {
+	suite.Suite
+}
+
+func middlewareTestAppend(value string) speedrail.Strategy[any, []string] {
+	return func(ctx context.Context, container any, model []string) (context.Context, []string, speedrail.Error) {
+		return ctx, append(model, value), nil
+	}
+}
+
+func middlewareTestTag(tag string) speedrail.Middleware[any, []string] {
+	return func(next speedrail.Strategy[any, []string]) speedrail.Strategy[any, []string] {
+		return func(ctx context.Context, container any, model []string) (context.Context, []string, speedrail.Error) {
+			return next(ctx, container, append(model, tag))
+		}
+	}
+}
+
+func middlewareTestIsEmpty(model []string) bool {
+	return len(model) == 0
+}
+
+func (suite *SpeedrailMiddlewareTestSuite) TestUse() {
+	plan := speedrail.Plan(
+		speedrail.If(speedrail.Not(middlewareTestIsEmpty), middlewareTestAppend("if")),
+		speedrail.IfElse(middlewareTestIsEmpty, middlewareTestAppend("unused"), speedrail.Group(
+			middlewareTestAppend("group"),
+		)),
+		speedrail.Merge(middlewareTestAppend("merge")),
+	).Use(middlewareTestTag("<"))
+
+	_, model, err := plan.Execute(context.Background(), nil, []string{"start"})
+	suite.NoError(err)
+	suite.Equal([]string{"start", "<", "<", "if", "<", "<", "<", "group", "<", "<", "merge"}, model)
+}
+
+func (suite *SpeedrailMiddlewareTestSuite) TestOrder() {
+	plan := speedrail.Plan(middlewareTestAppend("a")).Use(middlewareTestTag("1"), middlewareTestTag("2"))
+
+	_, model, err := plan.Execute(context.Background(), nil, nil)
+	suite.NoError(err)
+	suite.Equal([]string{"1", "2", "a"}, model)
+}
+
+func (suite *SpeedrailMiddlewareTestSuite) TestGroupUse() {
+	plan := speedrail.Plan(
+		speedrail.Group(
+			middlewareTestAppend("a"),
+			speedrail.Group(middlewareTestAppend("b")).Use(middlewareTestTag("inner")),
+		),
+		middlewareTestAppend("c"),
+	).Use(middlewareTestTag("outer"))
+
+	_, model, err := plan.Execute(context.Background(), nil, nil)
+	suite.NoError(err)
+	suite.Equal([]string{"outer", "outer", "a", "outer", "inner", "outer", "inner", "b", "outer", "c"}, model)
+
+	plan = speedrail.Plan(
+		speedrail.Group(middlewareTestAppend("a")).Use(middlewareTestTag("group")),
+		middlewareTestAppend("b"),
+	)
+
+	_, model, err = plan.Execute(context.Background(), nil, nil)
+	suite.NoError(err)
+	suite.Equal([]string{"group", "group", "a", "b"}, model)
+}
+
+func (suite *SpeedrailMiddlewareTestSuite) TestRecovery() {
+	recovery := func(next speedrail.Strategy[any, any]) speedrail.Strategy[any, any] {
+		return func(ctx context.Context, container any, model any) (resultCtx context.Context, resultModel any, err speedrail.Error) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					resultCtx, resultModel, err = ctx, model, speedrail.NewError(fmt.Errorf("panic: %v", recovered), http.StatusInternalServerError, "internal error")
+				}
+			}()
+
+			return next(ctx, container, model)
+		}
+	}
+
+	plan := speedrail.Plan(
+		speedrail.Group(func(ctx context.Context, container any, model any) (context.Context, any, speedrail.Error) {
+			panic(errors.New("boom"))
+		}),
+	).Use(recovery)
+
+	_, _, err := plan.Execute(context.Background(), nil, nil)
+	suite.Error(err)
+	suite.Equal("internal error", err.Error())
+	suite.Nil(speedrail.Speedrail[any, any](nil).Use(recovery))
+}
+
+func TestSpeedrailMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(SpeedrailMiddlewareTestSuite))
+}
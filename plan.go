@@ -0,0 +1,73 @@
+package speedrail
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Observer is notified when a plan that is nested with AsStrategy has been executed, with the name of the plan, the
+// resulting model and error, and the duration of the execution.
+type Observer[M any] func(ctx context.Context, name string, model M, err Error, duration time.Duration)
+
+// planConfig holds the options of a nested plan.
+type planConfig[C, M any] struct {
+	name        string
+	observers   []Observer[M]
+	middlewares []Middleware[C, M]
+}
+
+// PlanOption configures a plan that is nested with AsStrategy.
+type PlanOption[C, M any] func(*planConfig[C, M])
+
+// WithPlanName sets the name of the plan that is given to observers.
+func WithPlanName[C, M any](name string) PlanOption[C, M] {
+	return func(config *planConfig[C, M]) {
+		config.name = name
+	}
+}
+
+// WithObserver adds an observer that is notified every time the plan has been executed.
+func WithObserver[C, M any](observer Observer[M]) PlanOption[C, M] {
+	return func(config *planConfig[C, M]) {
+		config.observers = append(config.observers, observer)
+	}
+}
+
+// WithMiddleware wraps every strategy of the plan with middlewares, see Use.
+func WithMiddleware[C, M any](middlewares ...Middleware[C, M]) PlanOption[C, M] {
+	return func(config *planConfig[C, M]) {
+		config.middlewares = append(config.middlewares, middlewares...)
+	}
+}
+
+// AsStrategy will return the plan as a strategy, so that it can be nested in another plan. The plan is executed with
+// Execute, so an empty plan or a strategy that returns no context results in an error, like when the plan is executed on
+// its own. Nil strategies, middlewares or observers make the returned strategy fail with ErrInvalidPlan. Middlewares of
+// the outer plan wrap the strategies of the nested plan as well.
+func (s Speedrail[C, M]) AsStrategy(options ...PlanOption[C, M]) Strategy[C, M] {
+	var config planConfig[C, M]
+	for _, option := range options {
+		option(&config)
+	}
+
+	problems := append(strategyProblems(s), middlewareProblems(config.middlewares)...)
+	for index, observer := range config.observers {
+		problems = append(problems, nilProblem(fmt.Sprintf("observer %d", index), observer == nil)...)
+	}
+
+	plan := s
+	if len(config.middlewares) > 0 {
+		plan = s.Use(config.middlewares...)
+	}
+
+	return validated("AsStrategy", problems, func(ctx context.Context, container C, model M) (context.Context, M, Error) {
+		start := time.Now()
+		ctx, model, err := plan.Execute(ctx, container, model)
+		for _, observer := range config.observers {
+			observer(ctx, config.name, model, err, time.Since(start))
+		}
+
+		return ctx, model, err
+	})
+}
@@ -0,0 +1,51 @@
+package speedrail_test
+
+import (
+	"context"
+	"github.com/Kansuler/speedrail"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type SpeedrailPlanTestSuite struct {
+	suite.Suite
+}
+
+func (suite *SpeedrailPlanTestSuite) TestAsStrategy() {
+	var observed []string
+	nested := speedrail.Plan(middlewareTestAppend("a"), middlewareTestAppend("b")).AsStrategy(
+		speedrail.WithPlanName[any, []string]("nested"),
+		speedrail.WithObserver[any, []string](func(ctx context.Context, name string, model []string, err speedrail.Error, duration time.Duration) {
+			suite.NoError(err)
+			suite.GreaterOrEqual(duration, time.Duration(0))
+			observed = append(observed, name)
+		}),
+		speedrail.WithMiddleware[any, []string](middlewareTestTag("inner")),
+	)
+
+	plan := speedrail.Plan(nested, middlewareTestAppend("c")).Use(middlewareTestTag("outer"))
+
+	_, model, err := plan.Execute(context.Background(), nil, nil)
+	suite.NoError(err)
+	suite.Equal([]string{"outer", "outer", "inner", "a", "outer", "inner", "b", "outer", "c"}, model)
+	suite.Equal([]string{"nested"}, observed)
+}
+
+func (suite *SpeedrailPlanTestSuite) TestAsStrategyNoContext() {
+	nested := speedrail.Plan(func(ctx context.Context, container any, model any) (context.Context, any, speedrail.Error) {
+		return nil, model, nil
+	}).AsStrategy()
+
+	ctx, _, err := speedrail.Plan(nested).Execute(context.Background(), nil, nil)
+	suite.ErrorIs(err, speedrail.ErrNoContextReturned)
+	suite.NotNil(ctx)
+	suite.Len(err.Trail(), 1)
+
+	_, _, err = speedrail.Plan(speedrail.Speedrail[any, any](nil).AsStrategy()).Execute(context.Background(), nil, nil)
+	suite.ErrorIs(err, speedrail.ErrNoStrategy)
+}
+
+func TestSpeedrailPlanTestSuite(t *testing.T) {
+	suite.Run(t, new(SpeedrailPlanTestSuite))
+}
@@ -0,0 +1,106 @@
+package speedrail
+
+import (
+	"strings"
+)
+
+// ProblemContentType is the media type of a problem details response.
+const ProblemContentType = "application/problem+json"
+
+// ProblemError is an entry of the errors extension of a problem, derived from the trail of an error.
+type ProblemError struct {
+	Strategy string  `json:"strategy"`
+	Error    string  `json:"error"`
+	Stack    []Frame `json:"stack,omitempty"`
+}
+
+// Problem is the problem details of an error, as defined by RFC 7807.
+type Problem struct {
+	Type     string         `json:"type"`
+	Title    string         `json:"title"`
+	Status   int            `json:"status"`
+	Detail   string         `json:"detail,omitempty"`
+	Instance string         `json:"instance,omitempty"`
+	Errors   []ProblemError `json:"errors,omitempty"`
+}
+
+// problemConfig holds the settings used when creating a problem.
+type problemConfig struct {
+	typeURI   string
+	instance  string
+	showTrail bool
+}
+
+// ProblemOption configures the problem created by NewProblem.
+type ProblemOption func(*problemConfig)
+
+// WithProblemType sets the URI that identifies the type of the problem, by default it is about:blank.
+func WithProblemType(uri string) ProblemOption {
+	return func(config *problemConfig) {
+		config.typeURI = uri
+	}
+}
+
+// WithProblemInstance sets the URI that identifies the occurrence of the problem, usually the path of the request.
+func WithProblemInstance(uri string) ProblemOption {
+	return func(config *problemConfig) {
+		config.instance = uri
+	}
+}
+
+// WithProblemTrail decides if the trail of the error is rendered in the errors extension. By default, the trail is only
+// rendered in debug builds, as it holds internal errors that should not be exposed in production.
+func WithProblemTrail(show bool) ProblemOption {
+	return func(config *problemConfig) {
+		config.showTrail = show
+	}
+}
+
+// NewProblem will return the problem details of an error.
+func NewProblem(err Error, options ...ProblemOption) Problem {
+	config := problemConfig{typeURI: "about:blank", showTrail: Debug}
+	for _, option := range options {
+		option(&config)
+	}
+
+	status := err.StatusCode()
+	if status == 0 {
+		status = CategoryOf(err).StatusCode()
+	}
+
+	problem := Problem{
+		Type:     config.typeURI,
+		Title:    statusText(status),
+		Status:   status,
+		Detail:   err.Error(),
+		Instance: config.instance,
+	}
+
+	if config.showTrail {
+		for _, entry := range err.Trail() {
+			problemError := ProblemError{
+				Strategy: shortStrategyName(entry.StrategyName),
+				Stack:    entry.Stack,
+			}
+
+			if entry.Error != nil {
+				problemError.Error = entry.Error.Error()
+			}
+
+			problem.Errors = append(problem.Errors, problemError)
+		}
+	}
+
+	return problem
+}
+
+// shortStrategyName removes the package path from a strategy name, keeping the package name and the path that Focus
+// prefixes it with.
+func shortStrategyName(name string) string {
+	prefix := ""
+	if index := strings.LastIndex(name, ": "); index >= 0 {
+		prefix, name = name[:index+2], name[index+2:]
+	}
+
+	return prefix + name[strings.LastIndex(name, "/")+1:]
+}
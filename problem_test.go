@@ -0,0 +1,64 @@
+package speedrail_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/Kansuler/speedrail"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"testing"
+)
+
+type SpeedrailProblemTestSuite struct {
+	suite.Suite
+}
+
+func problemTestError() speedrail.Error {
+	return speedrail.NewError(errors.New("pq: duplicate key"), http.StatusConflict, "user already exists")
+}
+
+func (suite *SpeedrailProblemTestSuite) TestNewProblem() {
+	problem := speedrail.NewProblem(problemTestError(), speedrail.WithProblemInstance("/users"), speedrail.WithProblemTrail(false))
+	b, err := json.Marshal(problem)
+	suite.NoError(err)
+	suite.JSONEq(`{"type":"about:blank","title":"Conflict","status":409,"detail":"user already exists","instance":"/users"}`, string(b))
+
+	problem = speedrail.NewProblem(
+		problemTestError().Merge(speedrail.NewError(nil, http.StatusBadRequest, "invalid email")),
+		speedrail.WithProblemType("https://example.com/problems/signup"),
+		speedrail.WithProblemTrail(true),
+	)
+	b, err = json.Marshal(problem)
+	suite.NoError(err)
+	suite.JSONEq(`{
+		"type":"https://example.com/problems/signup",
+		"title":"Conflict",
+		"status":409,
+		"detail":"user already exists; invalid email",
+		"errors":[
+			{"strategy":"speedrail_test.problemTestError","error":"pq: duplicate key"},
+			{"strategy":"speedrail_test.(*SpeedrailProblemTestSuite).TestNewProblem","error":"invalid email"}
+		]
+	}`, string(b))
+}
+
+func (suite *SpeedrailProblemTestSuite) TestClientClosedRequest() {
+	problem := speedrail.NewProblem(speedrail.FromError(context.Canceled))
+	suite.Equal(speedrail.StatusClientClosedRequest, problem.Status)
+	suite.Equal("Client Closed Request", problem.Title)
+}
+
+func (suite *SpeedrailProblemTestSuite) TestNilTrailError() {
+	problem := speedrail.NewProblem(speedrail.ErrorFromTrail(speedrail.Trail{{StrategyName: "x"}}, http.StatusInternalServerError, "boom"), speedrail.WithProblemTrail(true))
+	suite.Equal([]speedrail.ProblemError{{Strategy: "x"}}, problem.Errors)
+}
+
+func (suite *SpeedrailProblemTestSuite) TestDefaultTrail() {
+	problem := speedrail.NewProblem(problemTestError())
+	suite.Equal(speedrail.Debug, problem.Errors != nil)
+}
+
+func TestSpeedrailProblemTestSuite(t *testing.T) {
+	suite.Run(t, new(SpeedrailProblemTestSuite))
+}
@@ -0,0 +1,147 @@
+package speedrail
+
+import (
+	"errors"
+	"net/http"
+)
+
+// RedactionRule decides what of an entry in a trail is safe to show to a client. It returns the entry to show and true if
+// the rule applies to the entry, otherwise false.
+type RedactionRule func(ErrorWithTrail) (ErrorWithTrail, bool)
+
+// RedactionPolicy is a list of rules that are tried in order for every entry in a trail, the first rule that applies
+// decides what is shown of the entry. Entries that no rule applies to are shown as they are.
+type RedactionPolicy []RedactionRule
+
+// DefaultRedactionPolicy is the policy used by Public, it redacts server errors.
+var DefaultRedactionPolicy = RedactionPolicy{RedactServerErrors}
+
+// AllowStatusCodes shows entries with one of the given status codes as they are, so that later rules do not apply.
+func AllowStatusCodes(statusCodes ...int) RedactionRule {
+	return func(entry ErrorWithTrail) (ErrorWithTrail, bool) {
+		for _, statusCode := range statusCodes {
+			if entry.StatusCode == statusCode {
+				return entry, true
+			}
+		}
+
+		return entry, false
+	}
+}
+
+// RedactStatusCodes redacts entries with one of the given status codes.
+func RedactStatusCodes(statusCodes ...int) RedactionRule {
+	return func(entry ErrorWithTrail) (ErrorWithTrail, bool) {
+		for _, statusCode := range statusCodes {
+			if entry.StatusCode == statusCode {
+				return redactEntry(entry), true
+			}
+		}
+
+		return entry, false
+	}
+}
+
+// RedactServerErrors redacts entries with a 5xx status code.
+func RedactServerErrors(entry ErrorWithTrail) (ErrorWithTrail, bool) {
+	if entry.StatusCode >= 500 && entry.StatusCode < 600 {
+		return redactEntry(entry), true
+	}
+
+	return entry, false
+}
+
+// RedactErrors redacts entries with an error that matches one of targets, as reported by errors.Is.
+func RedactErrors(targets ...error) RedactionRule {
+	return func(entry ErrorWithTrail) (ErrorWithTrail, bool) {
+		for _, target := range targets {
+			if errors.Is(entry.Error, target) {
+				return redactEntry(entry), true
+			}
+		}
+
+		return entry, false
+	}
+}
+
+// RedactErrorType redacts entries with an error of type T, as reported by errors.As.
+func RedactErrorType[T error]() RedactionRule {
+	return func(entry ErrorWithTrail) (ErrorWithTrail, bool) {
+		var target T
+		if errors.As(entry.Error, &target) {
+			return redactEntry(entry), true
+		}
+
+		return entry, false
+	}
+}
+
+// redactEntry replaces the outgoing message of an entry with the text of its status code, and removes its details.
+func redactEntry(entry ErrorWithTrail) ErrorWithTrail {
+	entry.Message = statusText(entry.StatusCode)
+	entry.Code = ""
+	entry.Field = ""
+	entry.Metadata = nil
+	entry.MessageKey = ""
+	entry.MessageArgs = nil
+	return entry
+}
+
+// Redactor is implemented by errors that wrap another error, to keep the wrapper when the error is redacted.
+type Redactor interface {
+	Redact(RedactionPolicy) Error
+}
+
+// Redact will return a view of err that is safe to show to a client. The rules of policy decide what is shown of every
+// entry in the trail, and the internal error, strategy name and stack of every entry are always removed. If any entry
+// is redacted, the outgoing message of the error is replaced with the text of its status code. Errors that implement
+// Redactor are redacted by themselves.
+func Redact(err Error, policy RedactionPolicy) Error {
+	if err == nil {
+		return nil
+	}
+
+	if redactor, ok := err.(Redactor); ok {
+		return redactor.Redact(policy)
+	}
+
+	redacted := false
+	trail := make(Trail, 0, len(err.Trail()))
+	for _, entry := range err.Trail() {
+		for _, rule := range policy {
+			if result, ok := rule(entry); ok {
+				redacted = redacted || result.Message != entry.Message
+				entry = result
+				break
+			}
+		}
+
+		trail = append(trail, ErrorWithTrail{
+			Error:       errors.New(entry.Message),
+			StatusCode:  entry.StatusCode,
+			Message:     entry.Message,
+			Code:        entry.Code,
+			Field:       entry.Field,
+			Metadata:    entry.Metadata,
+			Category:    entry.Category,
+			MessageKey:  entry.MessageKey,
+			MessageArgs: entry.MessageArgs,
+		})
+	}
+
+	statusCode := err.StatusCode()
+	outgoingMessage := err.Error()
+	if redacted {
+		outgoingMessage = statusText(statusCode)
+		if outgoingMessage == "" {
+			outgoingMessage = http.StatusText(http.StatusInternalServerError)
+		}
+	}
+
+	return ErrorFromTrail(trail, statusCode, outgoingMessage)
+}
+
+// Public will return a view of err that is safe to show to a client, redacted with DefaultRedactionPolicy.
+func Public(err Error) Error {
+	return Redact(err, DefaultRedactionPolicy)
+}
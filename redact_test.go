@@ -0,0 +1,86 @@
+package speedrail_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/Kansuler/speedrail"
+	"github.com/stretchr/testify/suite"
+	"io/fs"
+	"net/http"
+	"testing"
+)
+
+type SpeedrailRedactTestSuite struct {
+	suite.Suite
+}
+
+type redactTestError struct {
+	host string
+}
+
+func (e redactTestError) Error() string {
+	return "could not connect to " + e.host
+}
+
+func (suite *SpeedrailRedactTestSuite) TestPublic() {
+	err := speedrail.NewError(errors.New("invalid email"), http.StatusBadRequest, "invalid email", speedrail.WithField("email")).
+		Merge(speedrail.NewError(errors.New("pq: relation users does not exist"), http.StatusInternalServerError, "could not save user", speedrail.WithMeta("table", "users")))
+
+	public := speedrail.Public(err)
+	suite.Equal(http.StatusInternalServerError, public.StatusCode())
+	suite.Equal("Internal Server Error", public.Error())
+	suite.Require().Len(public.Trail(), 2)
+	suite.Equal("invalid email", public.Trail()[0].Message)
+	suite.Equal("email", public.Trail()[0].Field)
+	suite.Equal("Internal Server Error", public.Trail()[1].Message)
+	suite.Nil(public.Trail()[1].Metadata)
+
+	for _, entry := range public.Trail() {
+		suite.Empty(entry.StrategyName)
+		suite.Equal(entry.Message, entry.Error.Error())
+	}
+
+	data, marshalErr := json.Marshal(public.Trail())
+	suite.NoError(marshalErr)
+	suite.NotContains(string(data), "pq:")
+	suite.NotContains(string(data), "SpeedrailRedactTestSuite")
+
+	data, marshalErr = public.MarshalJSON()
+	suite.NoError(marshalErr)
+	suite.JSONEq(`{"error":"Internal Server Error","details":[{"field":"email","message":"invalid email"}]}`, string(data))
+
+	suite.Equal("invalid email", speedrail.Public(speedrail.NewError(errors.New("invalid email"), http.StatusBadRequest, "invalid email")).Error())
+}
+
+func (suite *SpeedrailRedactTestSuite) TestRedactionRules() {
+	err := speedrail.NewError(redactTestError{host: "db.internal"}, http.StatusBadGateway, "could not reach db.internal").
+		Merge(speedrail.NewError(fs.ErrNotExist, http.StatusNotFound, "open /var/data/users.json"))
+
+	redacted := speedrail.Redact(err, speedrail.RedactionPolicy{
+		speedrail.RedactErrorType[redactTestError](),
+		speedrail.RedactErrors(fs.ErrNotExist),
+	})
+	suite.Equal("Bad Gateway", redacted.Error())
+	suite.Equal("Bad Gateway", redacted.Trail()[0].Message)
+	suite.Equal("Not Found", redacted.Trail()[1].Message)
+
+	redacted = speedrail.Redact(err, speedrail.RedactionPolicy{
+		speedrail.AllowStatusCodes(http.StatusBadGateway),
+		speedrail.RedactServerErrors,
+		speedrail.RedactStatusCodes(http.StatusNotFound),
+	})
+	suite.Equal("Bad Gateway", redacted.Error())
+	suite.Equal("could not reach db.internal", redacted.Trail()[0].Message)
+	suite.Equal("Not Found", redacted.Trail()[1].Message)
+
+	redacted = speedrail.Redact(err, nil)
+	suite.Equal(err.Error(), redacted.Error())
+	suite.Equal("open /var/data/users.json", redacted.Trail()[1].Message)
+	suite.False(errors.Is(redacted, fs.ErrNotExist))
+
+	suite.Nil(speedrail.Redact(nil, speedrail.DefaultRedactionPolicy))
+}
+
+func TestSpeedrailRedactTestSuite(t *testing.T) {
+	suite.Run(t, new(SpeedrailRedactTestSuite))
+}
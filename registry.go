@@ -0,0 +1,204 @@
+package speedrail
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ErrorMapping is the status code, outgoing message and options of the speedrail error that a plain error is wrapped in.
+type ErrorMapping struct {
+	StatusCode int
+	// Message is the outgoing message, the text of the status code is used if it is empty.
+	Message string
+	Options []ErrorOption
+}
+
+// ErrorMapper maps a plain error, such as sql.ErrNoRows, to a speedrail error. It returns false if it does not handle
+// the error.
+type ErrorMapper func(error) (ErrorMapping, bool)
+
+// MapError maps errors that match target, as reported by errors.Is.
+func MapError(target error, statusCode int, outgoingMessage string, options ...ErrorOption) ErrorMapper {
+	return func(err error) (ErrorMapping, bool) {
+		if errors.Is(err, target) {
+			return ErrorMapping{StatusCode: statusCode, Message: outgoingMessage, Options: options}, true
+		}
+
+		return ErrorMapping{}, false
+	}
+}
+
+// MapErrorType maps errors of type T, as reported by errors.As.
+func MapErrorType[T error](statusCode int, outgoingMessage string, options ...ErrorOption) ErrorMapper {
+	return func(err error) (ErrorMapping, bool) {
+		var target T
+		if errors.As(err, &target) {
+			return ErrorMapping{StatusCode: statusCode, Message: outgoingMessage, Options: options}, true
+		}
+
+		return ErrorMapping{}, false
+	}
+}
+
+// ErrorRegistry is a registry of mappers that wraps plain errors into speedrail errors, so that the mapping of errors
+// from downstream clients lives in one place.
+type ErrorRegistry struct {
+	mutex   sync.RWMutex
+	mappers []ErrorMapper
+}
+
+// NewErrorRegistry will return a registry with the given mappers.
+func NewErrorRegistry(mappers ...ErrorMapper) *ErrorRegistry {
+	registry := &ErrorRegistry{}
+	registry.Register(mappers...)
+	return registry
+}
+
+// DefaultErrorRegistry is the registry used by Wrap and FromError. It maps well known errors of the standard library,
+// and errors that are not mapped are internal server errors.
+var DefaultErrorRegistry = NewErrorRegistry(
+	MapError(context.DeadlineExceeded, http.StatusGatewayTimeout, "", WithCategory(CategoryTransient)),
+	MapError(context.Canceled, StatusClientClosedRequest, ""),
+	MapError(fs.ErrNotExist, http.StatusNotFound, "", WithCategory(CategoryNotFound)),
+	MapError(fs.ErrPermission, http.StatusForbidden, ""),
+	MapError(fs.ErrExist, http.StatusConflict, "", WithCategory(CategoryConflict)),
+	MapError(sql.ErrNoRows, http.StatusNotFound, "", WithCategory(CategoryNotFound)),
+	MapErrorType[*url.Error](http.StatusBadGateway, "", WithCategory(CategoryTransient)),
+)
+
+// RegisterErrorMapper will register mappers in DefaultErrorRegistry.
+func RegisterErrorMapper(mappers ...ErrorMapper) {
+	DefaultErrorRegistry.Register(mappers...)
+}
+
+// Register will register mappers in the registry. The mappers are tried in order, and before the mappers that were
+// registered earlier, so that they override them.
+func (r *ErrorRegistry) Register(mappers ...ErrorMapper) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.mappers = append(append([]ErrorMapper{}, mappers...), r.mappers...)
+}
+
+// Map returns the mapping of err by the first mapper that handles it, or an internal server error if no mapper does.
+func (r *ErrorRegistry) Map(err error) ErrorMapping {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	for _, mapper := range r.mappers {
+		if mapping, ok := mapper(err); ok {
+			return mapping
+		}
+	}
+
+	return ErrorMapping{StatusCode: http.StatusInternalServerError}
+}
+
+// Wrap will wrap err into a speedrail error with the status code and outgoing message picked from the registry. The
+// options are applied after the options of the mapping.
+func (r *ErrorRegistry) Wrap(err error, options ...ErrorOption) Error {
+	return r.wrap(callerName(2), captureStack(2), err, options)
+}
+
+// Wrap will wrap err into a speedrail error with the status code and outgoing message picked from DefaultErrorRegistry.
+// The options are applied after the options of the mapping.
+func Wrap(err error, options ...ErrorOption) Error {
+	return DefaultErrorRegistry.wrap(callerName(2), captureStack(2), err, options)
+}
+
+// wrap wraps err into a speedrail error, with the given strategy name and stack in the trail. Speedrail errors are
+// returned as they are, and errors joined with errors.Join are merged with an entry in the trail each.
+func (r *ErrorRegistry) wrap(strategyName string, stack []Frame, err error, options []ErrorOption) Error {
+	if err == nil {
+		return nil
+	}
+
+	if speedrailErr, ok := err.(Error); ok {
+		return speedrailErr
+	}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok && isJoined(err, joined.Unwrap()) {
+		var result Error
+		for _, err := range joined.Unwrap() {
+			if part := r.wrap(strategyName, stack, err, options); part != nil {
+				if result == nil {
+					result = part
+				} else {
+					result = result.Merge(part)
+				}
+			}
+		}
+
+		return result
+	}
+
+	var speedrailErr Error
+	if errors.As(err, &speedrailErr) {
+		return speedrailErr
+	}
+
+	mapping := r.Map(err)
+	if mapping.Message == "" {
+		mapping.Message = statusText(mapping.StatusCode)
+	}
+
+	return newError(strategyName, stack, err, mapping.StatusCode, mapping.Message, append(append([]ErrorOption{}, mapping.Options...), options...)...)
+}
+
+// isJoined reports if err is errs joined with errors.Join, whose message is the messages of errs on a line each. Errors
+// that wrap several errors with a message of their own, such as those of fmt.Errorf with more than one %w, are not
+// split, so that their message is kept.
+func isJoined(err error, errs []error) bool {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		if err != nil {
+			messages = append(messages, err.Error())
+		}
+	}
+
+	return err.Error() == strings.Join(messages, "\n")
+}
+
+// errorRegistryKey is the context key of the error registry.
+type errorRegistryKey struct{}
+
+// WithErrorRegistry will return a context that makes strategies created with Func wrap their errors with registry.
+// Execute a plan with the context to set the registry for the whole plan.
+func WithErrorRegistry(ctx context.Context, registry *ErrorRegistry) context.Context {
+	return context.WithValue(ctx, errorRegistryKey{}, registry)
+}
+
+// errorRegistry returns the error registry of the context, or DefaultErrorRegistry.
+func errorRegistry(ctx context.Context) *ErrorRegistry {
+	if ctx == nil {
+		return DefaultErrorRegistry
+	}
+
+	if registry, ok := ctx.Value(errorRegistryKey{}).(*ErrorRegistry); ok {
+		return registry
+	}
+
+	return DefaultErrorRegistry
+}
+
+// Func will return a strategy of a function that returns a plain error. When the plan is executed, errors returned by
+// the function are wrapped with the registry of the context, see WithErrorRegistry, or DefaultErrorRegistry.
+func Func[C, M any](fn func(context.Context, C, M) (context.Context, M, error)) Strategy[C, M] {
+	if fn == nil {
+		return validated[C, M]("Func", nilProblem("fn", true), nil)
+	}
+
+	name := funcFullName(fn)
+	return func(ctx context.Context, container C, model M) (context.Context, M, Error) {
+		resultCtx, model, err := fn(ctx, container, model)
+		if err != nil {
+			return resultCtx, model, errorRegistry(ctx).wrap(name, captureContextStack(ctx, 1), err, nil)
+		}
+
+		return resultCtx, model, nil
+	}
+}
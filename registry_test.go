@@ -0,0 +1,115 @@
+package speedrail_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Kansuler/speedrail"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+type SpeedrailRegistryTestSuite struct {
+	suite.Suite
+}
+
+type registryTestError struct {
+	reason string
+}
+
+func (e registryTestError) Error() string {
+	return "payment declined: " + e.reason
+}
+
+func (suite *SpeedrailRegistryTestSuite) TestWrap() {
+	err := speedrail.Wrap(fmt.Errorf("find user: %w", sql.ErrNoRows), speedrail.WithField("id"))
+	suite.Equal(http.StatusNotFound, err.StatusCode())
+	suite.Equal("Not Found", err.Error())
+	suite.Equal(speedrail.CategoryNotFound, speedrail.CategoryOf(err))
+	suite.Equal("id", err.Trail()[0].Field)
+	suite.Equal("github.com/Kansuler/speedrail_test.(*SpeedrailRegistryTestSuite).TestWrap", err.Trail()[0].StrategyName)
+	suite.True(errors.Is(err, sql.ErrNoRows))
+
+	err = speedrail.Wrap(&url.Error{Op: "Get", URL: "http://payments.internal", Err: errors.New("connection refused")})
+	suite.Equal(http.StatusBadGateway, err.StatusCode())
+	suite.True(speedrail.Retryable(err))
+
+	err = speedrail.Wrap(&url.Error{Op: "Get", URL: "http://payments.internal", Err: context.DeadlineExceeded})
+	suite.Equal(http.StatusGatewayTimeout, err.StatusCode())
+
+	err = speedrail.Wrap(errors.New("unknown"))
+	suite.Equal(http.StatusInternalServerError, err.StatusCode())
+	suite.Equal("Internal Server Error", err.Error())
+
+	first, second := errors.New("connection reset"), sql.ErrNoRows
+	err = speedrail.Wrap(fmt.Errorf("query user 7: %w, %w", first, second))
+	suite.Len(err.Trail(), 1)
+	suite.Equal(http.StatusNotFound, err.StatusCode())
+	suite.Equal("query user 7: connection reset, sql: no rows in result set", err.Trail()[0].Error.Error())
+	suite.True(errors.Is(err, first))
+
+	err = speedrail.Wrap(errors.Join(first, second))
+	suite.Len(err.Trail(), 2)
+	suite.Equal("Internal Server Error; Not Found", err.Error())
+
+	suite.Nil(speedrail.Wrap(nil))
+}
+
+func (suite *SpeedrailRegistryTestSuite) TestRegistry() {
+	registry := speedrail.NewErrorRegistry(
+		speedrail.MapErrorType[registryTestError](http.StatusPaymentRequired, "payment declined", speedrail.WithCode("PAYMENT_DECLINED")),
+		speedrail.MapError(sql.ErrNoRows, http.StatusNotFound, "no rows"),
+	)
+	registry.Register(speedrail.MapError(sql.ErrNoRows, http.StatusGone, ""))
+
+	err := registry.Wrap(registryTestError{reason: "insufficient funds"})
+	suite.Equal(http.StatusPaymentRequired, err.StatusCode())
+	suite.Equal("payment declined", err.Error())
+	suite.Equal("PAYMENT_DECLINED", speedrail.ErrorCode(err))
+	suite.Equal("github.com/Kansuler/speedrail_test.(*SpeedrailRegistryTestSuite).TestRegistry", err.Trail()[0].StrategyName)
+
+	err = registry.Wrap(errors.Join(sql.ErrNoRows, errors.New("other")))
+	suite.Equal(http.StatusInternalServerError, err.StatusCode())
+	suite.Equal("Gone; Internal Server Error", err.Error())
+	suite.Len(err.Trail(), 2)
+
+	mapping := registry.Map(context.Canceled)
+	suite.Equal(http.StatusInternalServerError, mapping.StatusCode)
+}
+
+func (suite *SpeedrailRegistryTestSuite) TestFunc() {
+	plan := speedrail.Plan(
+		speedrail.Func(func(ctx context.Context, container any, model string) (context.Context, string, error) {
+			return ctx, model, registryTestError{reason: "card expired"}
+		}),
+	)
+
+	_, _, err := plan.Execute(context.Background(), nil, "")
+	suite.Equal(http.StatusInternalServerError, err.StatusCode())
+	suite.Contains(err.Trail()[0].StrategyName, "TestFunc")
+
+	_, _, err = plan[0](nil, nil, "")
+	suite.Equal(http.StatusInternalServerError, err.StatusCode())
+
+	registry := speedrail.NewErrorRegistry(speedrail.MapErrorType[registryTestError](http.StatusPaymentRequired, ""))
+	_, _, err = plan.Execute(speedrail.WithErrorRegistry(context.Background(), registry), nil, "")
+	suite.Equal(http.StatusPaymentRequired, err.StatusCode())
+	suite.Equal("Payment Required", err.Error())
+
+	plan = speedrail.Plan(
+		speedrail.Func(func(ctx context.Context, container any, model string) (context.Context, string, error) {
+			return ctx, "done", nil
+		}),
+	)
+
+	_, model, err := plan.Execute(context.Background(), nil, "")
+	suite.NoError(err)
+	suite.Equal("done", model)
+}
+
+func TestSpeedrailRegistryTestSuite(t *testing.T) {
+	suite.Run(t, new(SpeedrailRegistryTestSuite))
+}
@@ -0,0 +1,135 @@
+package speedrail
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// StrategyTiming is the time it took to execute a strategy of a plan.
+type StrategyTiming struct {
+	Index    int
+	Strategy string
+	Duration time.Duration
+}
+
+// Branch is the result of a condition evaluated by If or IfElse.
+type Branch struct {
+	Condition string
+	Result    bool
+}
+
+// ExecutionResult is the result of Run, with the model and error together with metadata about the execution.
+type ExecutionResult[M any] struct {
+	// Context is the context returned by the last executed strategy.
+	Context context.Context
+	Model   M
+	Err     Error
+	// Warnings are the warnings emitted during the execution.
+	Warnings []Warning
+	// Timings are the durations of the strategies of the plan that were executed, in order.
+	Timings []StrategyTiming
+	// Branches are the conditions evaluated by If and IfElse, in order.
+	Branches []Branch
+	// Skipped are the names of strategies that were not executed, because a condition was false, a branch was not
+	// taken or the plan stopped on an error.
+	Skipped []string
+	// Duration is the total duration of the execution.
+	Duration time.Duration
+}
+
+// recorder records the branches taken and strategies skipped during an execution started with Run.
+type recorder struct {
+	mutex    sync.Mutex
+	branches []Branch
+	skipped  []string
+}
+
+// recorderKey is the context key of the recorder.
+type recorderKey struct{}
+
+// recordBranch records the result of a condition, and the strategy skipped because of it, if the context belongs to an
+// execution started with Run.
+func recordBranch(ctx context.Context, condition any, result bool, skipped any) {
+	if ctx == nil {
+		return
+	}
+
+	recorder, ok := ctx.Value(recorderKey{}).(*recorder)
+	if !ok {
+		return
+	}
+
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.branches = append(recorder.branches, Branch{Condition: funcName(condition), Result: result})
+	if skipped != nil {
+		recorder.skipped = append(recorder.skipped, funcName(skipped))
+	}
+}
+
+// funcName returns the name of a function value, without the package path.
+func funcName(fn any) string {
+	return shortStrategyName(funcFullName(fn))
+}
+
+// funcFullName returns the name of a function value, including the package path.
+func funcFullName(fn any) string {
+	value := reflect.ValueOf(fn)
+	if value.Kind() != reflect.Func || value.IsNil() {
+		return "unknown"
+	}
+
+	if function := runtime.FuncForPC(value.Pointer()); function != nil {
+		return function.Name()
+	}
+
+	return "unknown"
+}
+
+// Run executes the plan like Execute, and returns the result together with the warnings, timings, branches taken and
+// strategies skipped during the execution.
+func (s Speedrail[C, M]) Run(ctx context.Context, container C, model M) ExecutionResult[M] {
+	start := time.Now()
+	if s == nil {
+		ctx, model, err := s.Execute(ctx, container, model)
+		return ExecutionResult[M]{Context: ctx, Model: model, Err: err, Duration: time.Since(start)}
+	}
+
+	if ctx == nil {
+		return ExecutionResult[M]{Model: model, Err: noContextError(), Duration: time.Since(start)}
+	}
+
+	recorder := &recorder{}
+	ctx = withWarnings(context.WithValue(ctx, recorderKey{}, recorder))
+
+	var timings []StrategyTiming
+	timed := make(Speedrail[C, M], len(s))
+	for index, strategy := range s {
+		index, strategy := index, strategy
+		timed[index] = func(ctx context.Context, container C, model M) (context.Context, M, Error) {
+			strategyStart := time.Now()
+			ctx, model, err := strategy(ctx, container, model)
+			timings = append(timings, StrategyTiming{Index: index, Strategy: funcName(strategy), Duration: time.Since(strategyStart)})
+			return ctx, model, err
+		}
+	}
+
+	var result ExecutionResult[M]
+	result.Context, result.Model, result.Err = timed.execute(ctx, container, model, 0, nil)
+	result.Duration = time.Since(start)
+	result.Warnings = Warnings(ctx)
+	result.Timings = timings
+
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	result.Branches = recorder.branches
+	result.Skipped = recorder.skipped
+	for _, strategy := range s[len(timings):] {
+		result.Skipped = append(result.Skipped, funcName(strategy))
+	}
+
+	return result
+}
@@ -0,0 +1,72 @@
+package speedrail_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Kansuler/speedrail"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type SpeedrailResultTestSuite struct {
+	suite.Suite
+}
+
+func resultTestIsEmpty(model string) bool {
+	return model == ""
+}
+
+func resultTestUpper(ctx context.Context, container any, model string) (context.Context, string, speedrail.Error) {
+	speedrail.Warn(ctx, speedrail.Warning{Message: "shouting"})
+	return ctx, strings.ToUpper(model), nil
+}
+
+func resultTestLower(ctx context.Context, container any, model string) (context.Context, string, speedrail.Error) {
+	return ctx, strings.ToLower(model), nil
+}
+
+func resultTestFail(ctx context.Context, container any, model string) (context.Context, string, speedrail.Error) {
+	return ctx, model, speedrail.NewError(errors.New("failed"), http.StatusBadRequest, "failed")
+}
+
+func (suite *SpeedrailResultTestSuite) TestRun() {
+	plan := speedrail.Plan(
+		speedrail.If(resultTestIsEmpty, resultTestFail),
+		speedrail.IfElse(speedrail.Not(resultTestIsEmpty), resultTestUpper, resultTestLower),
+	)
+
+	result := plan.Run(context.Background(), nil, "john")
+	suite.NoError(result.Err)
+	suite.Equal("JOHN", result.Model)
+	suite.NotNil(result.Context)
+	suite.Equal([]speedrail.Warning{{Message: "shouting"}}, result.Warnings)
+	suite.Require().Len(result.Timings, 2)
+	suite.Equal(0, result.Timings[0].Index)
+	suite.Equal(1, result.Timings[1].Index)
+	suite.Contains(result.Timings[0].Strategy, "speedrail.If[")
+	suite.Require().Len(result.Branches, 2)
+	suite.Equal(speedrail.Branch{Condition: "speedrail_test.resultTestIsEmpty", Result: false}, result.Branches[0])
+	suite.True(result.Branches[1].Result)
+	suite.Equal([]string{"speedrail_test.resultTestFail", "speedrail_test.resultTestLower"}, result.Skipped)
+	suite.GreaterOrEqual(result.Duration, result.Timings[0].Duration+result.Timings[1].Duration)
+}
+
+func (suite *SpeedrailResultTestSuite) TestRunError() {
+	plan := speedrail.Plan(resultTestUpper, resultTestFail, resultTestLower)
+
+	result := plan.Run(context.Background(), nil, "john")
+	suite.Error(result.Err)
+	suite.Equal("JOHN", result.Model)
+	suite.Len(result.Timings, 2)
+	suite.Empty(result.Branches)
+	suite.Equal([]string{"speedrail_test.resultTestLower"}, result.Skipped)
+
+	result = speedrail.Speedrail[any, string](nil).Run(context.Background(), nil, "john")
+	suite.ErrorIs(result.Err, speedrail.ErrNoStrategy)
+}
+
+func TestSpeedrailResultTestSuite(t *testing.T) {
+	suite.Run(t, new(SpeedrailResultTestSuite))
+}
@@ -20,22 +20,44 @@ var ErrNoStrategy = errors.New("no strategies to execute")
 // ErrNoContextReturned is the error returned when no context is returned by a strategy.
 var ErrNoContextReturned = errors.New("no context returned by strategy")
 
-// Execute executes a list of strategies.
+// ErrNoContext is the error returned when a plan is executed with a nil context.
+var ErrNoContext = errors.New("no context given to plan")
+
+// noContextError will return the error for a plan that is executed with a nil context.
+func noContextError() Error {
+	return NewError(ErrNoContext, http.StatusInternalServerError, "no context given to plan")
+}
+
+// Execute executes a list of strategies. Warnings emitted with Warn are returned by Warnings with the returned context.
 func (s Speedrail[C, M]) Execute(ctx context.Context, container C, model M) (context.Context, M, Error) {
 	if s == nil {
 		return ctx, model, NewError(ErrNoStrategy, http.StatusInternalServerError, "no strategies to execute")
 	}
 
-	for _, strategy := range s {
-		var err Error
-		ctx, model, err = strategy(ctx, container, model)
-		if ctx == nil {
-			return ctx, model, NewError(ErrNoContextReturned, http.StatusInternalServerError, "no context returned by strategy")
-		}
+	return s.execute(ctx, container, model, 0, nil)
+}
 
+// execute executes the strategies in order from index start. If completed is given, it is called with the index of
+// every strategy that finished without error, and any error it returns will stop the execution. Warnings emitted by the
+// strategies are collected on the context. A nil context fails with ErrNoContext.
+func (s Speedrail[C, M]) execute(ctx context.Context, container C, model M, start int, completed func(int, M) Error) (context.Context, M, Error) {
+	if ctx == nil {
+		return ctx, model, noContextError()
+	}
+
+	ctx = withWarnings(ctx)
+	for index := start; index < len(s); index++ {
+		var err Error
+		ctx, model, err = run(ctx, s[index], container, model)
 		if err != nil {
 			return ctx, model, err
 		}
+
+		if completed != nil {
+			if err = completed(index, model); err != nil {
+				return ctx, model, err
+			}
+		}
 	}
 
 	return ctx, model, nil
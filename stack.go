@@ -0,0 +1,156 @@
+package speedrail
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// maxStackDepth is the maximum number of frames captured in a stack.
+const maxStackDepth = 32
+
+// captureStacks decides if stacks are captured for every error.
+var captureStacks atomic.Bool
+
+// CaptureStacks enables, or disables, capturing of the stack for every error created with NewError or FromError. It is
+// disabled by default, as capturing stacks is costly.
+func CaptureStacks(enabled bool) {
+	captureStacks.Store(enabled)
+}
+
+// stackCaptureKey is the context key that enables capturing of stacks for a single execution.
+type stackCaptureKey struct{}
+
+// WithStackCapture will return a context that enables capturing of stacks for the plans executed with it, without
+// enabling CaptureStacks for every error. Errors returned by strategies that have no stack get the stack of the plan
+// where the strategy was executed, with the strategy as first frame, and plain errors wrapped by Func get the stack where
+// they were wrapped.
+func WithStackCapture(ctx context.Context) context.Context {
+	return context.WithValue(ctx, stackCaptureKey{}, true)
+}
+
+// stackCapture reports if capturing of stacks is enabled on the context.
+func stackCapture(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+
+	enabled, _ := ctx.Value(stackCaptureKey{}).(bool)
+	return enabled
+}
+
+// Frame is a frame of the stack where an error was created.
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// WithStack captures the stack for the error, even if CaptureStacks is not enabled.
+func WithStack() ErrorOption {
+	return func(entry *ErrorWithTrail) {
+		entry.Stack = callerStack()
+	}
+}
+
+// packagePrefix is the prefix of the names of the functions in this package.
+var packagePrefix = strings.TrimSuffix(runtime.FuncForPC(reflect.ValueOf(stack).Pointer()).Name(), "stack")
+
+// callerStack returns the stack from the first caller outside this package, such as the strategy that created an error.
+func callerStack() []Frame {
+	frames := stack(2)
+	for index, frame := range frames {
+		if !strings.HasPrefix(frame.Function, packagePrefix) {
+			return frames[index:]
+		}
+	}
+
+	return frames
+}
+
+// captureStack returns the stack skip frames up, where 1 is the caller of captureStack, if CaptureStacks is enabled.
+func captureStack(skip int) []Frame {
+	if !captureStacks.Load() {
+		return nil
+	}
+
+	return stack(skip + 1)
+}
+
+// captureContextStack returns the stack skip frames up, where 1 is the caller of captureContextStack, if CaptureStacks
+// or WithStackCapture is enabled.
+func captureContextStack(ctx context.Context, skip int) []Frame {
+	if !captureStacks.Load() && !stackCapture(ctx) {
+		return nil
+	}
+
+	return stack(skip + 1)
+}
+
+// withPlanStack will return err with the stack of the plan, skip frames up where 1 is the caller of withPlanStack,
+// added to the entries of the trail that have no stack, with the strategy that returned err as first frame.
+func withPlanStack(ctx context.Context, err Error, strategy any, skip int) Error {
+	if err == nil || !stackCapture(ctx) {
+		return err
+	}
+
+	var frames []Frame
+	if fn := runtime.FuncForPC(reflect.ValueOf(strategy).Pointer()); fn != nil {
+		file, line := fn.FileLine(fn.Entry())
+		frames = append(frames, Frame{Function: fn.Name(), File: file, Line: line})
+	}
+
+	frames = append(frames, stack(skip+1)...)
+	return MapTrail(err, func(entry ErrorWithTrail) ErrorWithTrail {
+		if entry.Stack == nil {
+			entry.Stack = frames
+		}
+
+		return entry
+	})
+}
+
+// stack returns the stack skip frames up, where 1 is the caller of stack.
+func stack(skip int) []Frame {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip+1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var result []Frame
+	for {
+		frame, more := frames.Next()
+		result = append(result, Frame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		if !more {
+			return result
+		}
+	}
+}
+
+// Format formats the error. The %+v verb prints every entry of the trail together with its stack, other verbs print the
+// outgoing message.
+func (e defaultError) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if f.Flag('+') {
+			_, _ = io.WriteString(f, e.Error())
+			for index, entry := range e.trail {
+				_, _ = fmt.Fprintf(f, "\n[%d]%s: %v", index+1, entry.StrategyName, entry.Error)
+				for _, frame := range entry.Stack {
+					_, _ = fmt.Fprintf(f, "\n\t%s\n\t\t%s:%d", frame.Function, frame.File, frame.Line)
+				}
+			}
+
+			return
+		}
+
+		_, _ = io.WriteString(f, e.Error())
+	case 's':
+		_, _ = io.WriteString(f, e.Error())
+	case 'q':
+		_, _ = fmt.Fprintf(f, "%q", e.Error())
+	}
+}
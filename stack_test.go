@@ -0,0 +1,104 @@
+package speedrail_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Kansuler/speedrail"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type SpeedrailStackTestSuite struct {
+	suite.Suite
+}
+
+func stackTestFunction() speedrail.Error {
+	return speedrail.NewError(errors.New("internal"), http.StatusInternalServerError, "outgoing")
+}
+
+func (suite *SpeedrailStackTestSuite) TestCaptureStacks() {
+	suite.Nil(stackTestFunction().Trail()[0].Stack)
+
+	speedrail.CaptureStacks(true)
+	defer speedrail.CaptureStacks(false)
+
+	err := stackTestFunction()
+	stack := err.Trail()[0].Stack
+	suite.Require().NotEmpty(stack)
+	suite.Equal("github.com/Kansuler/speedrail_test.stackTestFunction", stack[0].Function)
+	suite.True(strings.HasSuffix(stack[0].File, "stack_test.go"))
+	suite.Equal(20, stack[0].Line)
+
+	fromErr := speedrail.FromError(errors.New("plain"))
+	suite.Require().NotEmpty(fromErr.Trail()[0].Stack)
+	suite.Equal("github.com/Kansuler/speedrail_test.(*SpeedrailStackTestSuite).TestCaptureStacks", fromErr.Trail()[0].Stack[0].Function)
+}
+
+func (suite *SpeedrailStackTestSuite) TestWithStack() {
+	err := speedrail.NewError(errors.New("internal"), http.StatusInternalServerError, "outgoing", speedrail.WithStack())
+	stack := err.Trail()[0].Stack
+	suite.Require().NotEmpty(stack)
+	suite.Equal("github.com/Kansuler/speedrail_test.(*SpeedrailStackTestSuite).TestWithStack", stack[0].Function)
+	suite.Equal(42, stack[0].Line)
+
+	b, marshalErr := json.Marshal(err.Trail())
+	suite.NoError(marshalErr)
+	var trail speedrail.Trail
+	suite.NoError(json.Unmarshal(b, &trail))
+	suite.Equal(stack, trail[0].Stack)
+
+	problem := speedrail.NewProblem(err, speedrail.WithProblemTrail(true))
+	suite.Require().Len(problem.Errors, 1)
+	suite.Equal(stack, problem.Errors[0].Stack)
+}
+
+func (suite *SpeedrailStackTestSuite) TestFormat() {
+	err := speedrail.NewError(errors.New("internal"), http.StatusInternalServerError, "outgoing", speedrail.WithStack()).
+		Merge(stackTestFunction())
+
+	suite.Equal("outgoing; outgoing", fmt.Sprintf("%v", err))
+	suite.Equal("outgoing; outgoing", fmt.Sprintf("%s", err))
+	suite.Equal(`"outgoing; outgoing"`, fmt.Sprintf("%q", err))
+
+	verbose := fmt.Sprintf("%+v", err)
+	suite.True(strings.HasPrefix(verbose, "outgoing; outgoing\n[1]github.com/Kansuler/speedrail_test.(*SpeedrailStackTestSuite).TestFormat: internal\n"))
+	frame := err.Trail()[0].Stack[0]
+	suite.Contains(verbose, fmt.Sprintf("\t%s\n\t\t%s:%d\n", frame.Function, frame.File, frame.Line))
+	suite.Contains(verbose, "\n[2]github.com/Kansuler/speedrail_test.stackTestFunction: internal")
+}
+
+func stackTestStrategy(ctx context.Context, container any, model any) (context.Context, any, speedrail.Error) {
+	return ctx, model, speedrail.NewError(errors.New("internal"), http.StatusInternalServerError, "outgoing")
+}
+
+func (suite *SpeedrailStackTestSuite) TestWithStackCapture() {
+	plan := speedrail.Plan(speedrail.Group(stackTestStrategy))
+
+	_, _, err := plan.Execute(context.Background(), nil, nil)
+	suite.Nil(err.Trail()[0].Stack)
+
+	_, _, err = plan.Execute(speedrail.WithStackCapture(context.Background()), nil, nil)
+	stack := err.Trail()[0].Stack
+	suite.Require().NotEmpty(stack)
+	suite.Equal("github.com/Kansuler/speedrail_test.stackTestStrategy", stack[0].Function)
+	suite.True(strings.HasSuffix(stack[0].File, "stack_test.go"))
+
+	wrapped := speedrail.Func(func(ctx context.Context, container any, model any) (context.Context, any, error) {
+		return ctx, model, errors.New("plain")
+	})
+
+	_, _, err = speedrail.Plan(wrapped).Execute(context.Background(), nil, nil)
+	suite.Nil(err.Trail()[0].Stack)
+
+	_, _, err = speedrail.Plan(wrapped).Execute(speedrail.WithStackCapture(context.Background()), nil, nil)
+	suite.Require().NotEmpty(err.Trail()[0].Stack)
+	suite.Contains(err.Trail()[0].Stack[0].Function, "speedrail.Func")
+}
+
+func TestSpeedrailStackTestSuite(t *testing.T) {
+	suite.Run(t, new(SpeedrailStackTestSuite))
+}
@@ -7,63 +7,78 @@ type Strategy[C, M any] func(context.Context, C, M) (context.Context, M, Error)
 
 // If executes a strategy if the condition is true.
 func If[C, M any](condition Condition[M], onTrue Strategy[C, M]) Strategy[C, M] {
-	return func(ctx context.Context, container C, model M) (context.Context, M, Error) {
+	problems := append(conditionProblems("condition", condition), namedProblems("onTrue", onTrue)...)
+	return validated("If", problems, func(ctx context.Context, container C, model M) (context.Context, M, Error) {
 		if condition(model) {
-			return onTrue(ctx, container, model)
+			recordBranch(ctx, condition, true, nil)
+			return run(ctx, onTrue, container, model)
 		}
 
+		recordBranch(ctx, condition, false, onTrue)
 		return ctx, model, nil
-	}
+	})
 }
 
 // IfElse executes a strategy if the condition is true, otherwise execute another strategy.
 func IfElse[C, M any](condition Condition[M], onTrue Strategy[C, M], onFalse Strategy[C, M]) Strategy[C, M] {
-	return func(ctx context.Context, container C, model M) (context.Context, M, Error) {
+	problems := append(conditionProblems("condition", condition), namedProblems("onTrue", onTrue)...)
+	problems = append(problems, namedProblems("onFalse", onFalse)...)
+	return validated("IfElse", problems, func(ctx context.Context, container C, model M) (context.Context, M, Error) {
 		if condition(model) {
-			return onTrue(ctx, container, model)
+			recordBranch(ctx, condition, true, onFalse)
+			return run(ctx, onTrue, container, model)
 		}
 
-		return onFalse(ctx, container, model)
-	}
+		recordBranch(ctx, condition, false, onTrue)
+		return run(ctx, onFalse, container, model)
+	})
 }
 
 // Merge executes all strategies and will not stop on error, but merge all errors together and then return any error.
+// Errors are merged with the policy set by WithMergePolicy on the context, or DefaultMergePolicy.
 func Merge[C, M any](strategies ...Strategy[C, M]) Strategy[C, M] {
-	return func(ctx context.Context, container C, model M) (context.Context, M, Error) {
-		var resultErr Error
-		for _, strategy := range strategies {
-			var err Error
-			ctx, model, err = strategy(ctx, container, model)
-			if err == nil {
-				continue
-			}
+	return validated("Merge", strategyProblems(strategies), func(ctx context.Context, container C, model M) (context.Context, M, Error) {
+		return merge(ctx, container, model, mergePolicy(ctx), strategies)
+	})
+}
 
-			if resultErr == nil {
-				resultErr = err
-				continue
-			}
+// MergeUsing works like Merge, but merges the errors with the given policy.
+func MergeUsing[C, M any](policy MergePolicy, strategies ...Strategy[C, M]) Strategy[C, M] {
+	return validated("MergeUsing", strategyProblems(strategies), func(ctx context.Context, container C, model M) (context.Context, M, Error) {
+		return merge(ctx, container, model, policy, strategies)
+	})
+}
 
-			resultErr = resultErr.Merge(err)
+// merge executes all strategies, and merges their errors with policy.
+func merge[C, M any](ctx context.Context, container C, model M, policy MergePolicy, strategies []Strategy[C, M]) (context.Context, M, Error) {
+	var resultErr Error
+	for _, strategy := range strategies {
+		var err Error
+		ctx, model, err = run(ctx, strategy, container, model)
+		if err == nil {
+			continue
 		}
 
-		return ctx, model, resultErr
+		resultErr = MergeErrors(policy, resultErr, err)
 	}
+
+	return ctx, model, resultErr
 }
 
 // Group is a helper function that makes it easier to read strategies logically grouped together. They are executed in
 // order. If an error is returned, the execution of the strategies will stop and error returned.
 func Group[C, M any](strategies ...Strategy[C, M]) Strategy[C, M] {
-	return func(ctx context.Context, container C, model M) (context.Context, M, Error) {
+	return validated("Group", strategyProblems(strategies), func(ctx context.Context, container C, model M) (context.Context, M, Error) {
 		for _, strategy := range strategies {
 			var err Error
-			ctx, model, err = strategy(ctx, container, model)
+			ctx, model, err = run(ctx, strategy, container, model)
 			if err != nil {
 				return ctx, model, err
 			}
 		}
 
 		return ctx, model, nil
-	}
+	})
 }
 
 // ThrowError will return a defined error.
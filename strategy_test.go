@@ -49,6 +49,33 @@ func (suite *SpeedrailStrategyTestSuite) TestIf() {
 	suite.True(model.CriteriaMet)
 }
 
+func (suite *SpeedrailStrategyTestSuite) TestNilContext() {
+	strategy := func(ctx context.Context, container any, model strategyTestModel) (context.Context, strategyTestModel, speedrail.Error) {
+		model.CriteriaMet = true
+		return ctx, model, nil
+	}
+
+	never := func(model strategyTestModel) bool {
+		return false
+	}
+
+	ctx, model, err := speedrail.If[any, strategyTestModel](never, strategy)(nil, nil, strategyTestModel{})
+	suite.Nil(ctx)
+	suite.NoError(err)
+	suite.False(model.CriteriaMet)
+
+	for name, strategy := range map[string]speedrail.Strategy[any, strategyTestModel]{
+		"IfElse": speedrail.IfElse[any, strategyTestModel](never, strategy, strategy),
+		"Group":  speedrail.Group[any, strategyTestModel](strategy),
+		"Use":    speedrail.Strategy[any, strategyTestModel](strategy).Use(),
+	} {
+		ctx, model, err = strategy(nil, nil, strategyTestModel{})
+		suite.Nil(ctx, name)
+		suite.NoError(err, name)
+		suite.True(model.CriteriaMet, name)
+	}
+}
+
 func (suite *SpeedrailStrategyTestSuite) TestIfElse() {
 	plan := speedrail.Plan(
 		speedrail.IfElse(
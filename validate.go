@@ -0,0 +1,164 @@
+package speedrail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// ErrInvalidPlan is the error returned when a plan, or a helper function in it, is given a nil strategy or condition.
+var ErrInvalidPlan = errors.New("invalid plan")
+
+// invalidPlanError lists the problems of a plan, such as nil strategies, with their position in the plan.
+type invalidPlanError struct {
+	problems []string
+}
+
+// Error returns the problems of the plan.
+func (e invalidPlanError) Error() string {
+	return ErrInvalidPlan.Error() + ": " + strings.Join(e.problems, "; ")
+}
+
+// Is reports if target is ErrInvalidPlan.
+func (e invalidPlanError) Is(target error) bool {
+	return target == ErrInvalidPlan
+}
+
+// Validate reports nil strategies in the plan, and nil strategies or conditions given to the helper functions in the
+// plan, with their position, such as "strategy 1: Group: strategy 0 is nil". Helper functions that are given nil values
+// return a strategy that fails with ErrInvalidPlan when executed, so a plan can be validated right after construction
+// instead of panicking at execution time.
+func (s Speedrail[C, M]) Validate() error {
+	if len(s) == 0 {
+		return ErrNoStrategy
+	}
+
+	if problems := strategyProblems(s); len(problems) > 0 {
+		return invalidPlanError{problems: problems}
+	}
+
+	return nil
+}
+
+// strategyProblems returns the problems of strategies, prefixed with their index.
+func strategyProblems[C, M any](strategies []Strategy[C, M]) []string {
+	var problems []string
+	for index, strategy := range strategies {
+		problems = append(problems, namedProblems(fmt.Sprintf("strategy %d", index), strategy)...)
+	}
+
+	return problems
+}
+
+// namedProblems returns the problems of a strategy that is given to a helper function as name.
+func namedProblems[C, M any](name string, strategy Strategy[C, M]) []string {
+	if strategy == nil {
+		return []string{name + " is nil"}
+	}
+
+	if !isInvalid(strategy) {
+		return nil
+	}
+
+	var container C
+	var model M
+	_, _, err := strategy(context.Background(), container, model)
+
+	var planErr invalidPlanError
+	if !errors.As(err, &planErr) {
+		return nil
+	}
+
+	problems := make([]string, 0, len(planErr.problems))
+	for _, problem := range planErr.problems {
+		problems = append(problems, name+": "+problem)
+	}
+
+	return problems
+}
+
+// planProblems returns the problems of a nested plan that is given to a helper function as name. An empty plan is left to
+// fail with ErrNoStrategy when executed.
+func planProblems[C, M any](name string, plan Speedrail[C, M]) []string {
+	var problems []string
+	for _, problem := range strategyProblems(plan) {
+		problems = append(problems, name+": "+problem)
+	}
+
+	return problems
+}
+
+// middlewareProblems returns the problems of middlewares, prefixed with their index.
+func middlewareProblems[C, M any](middlewares []Middleware[C, M]) []string {
+	var problems []string
+	for index, middleware := range middlewares {
+		problems = append(problems, nilProblem(fmt.Sprintf("middleware %d", index), middleware == nil)...)
+	}
+
+	return problems
+}
+
+// conditionProblems returns the problem of a condition that is given to a helper function as name.
+func conditionProblems[M any](name string, condition Condition[M]) []string {
+	switch {
+	case condition == nil:
+		return []string{name + " is nil"}
+	case isInvalidCondition(condition):
+		return []string{name + " is given a nil condition"}
+	}
+
+	return nil
+}
+
+// nilProblem returns a problem if a value that is given to a helper function as name is nil.
+func nilProblem(name string, isNil bool) []string {
+	if isNil {
+		return []string{name + " is nil"}
+	}
+
+	return nil
+}
+
+// validated will return strategy, or a strategy that fails with the problems prefixed with the name of the helper
+// function if there are any.
+func validated[C, M any](name string, problems []string, strategy Strategy[C, M]) Strategy[C, M] {
+	if len(problems) == 0 {
+		return strategy
+	}
+
+	prefixed := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		prefixed = append(prefixed, name+": "+problem)
+	}
+
+	return invalid[C, M](invalidPlanError{problems: prefixed})
+}
+
+// invalid returns a strategy that fails with err. It is returned by helper functions that are given nil values, and
+// recognized by Validate.
+func invalid[C, M any](err error) Strategy[C, M] {
+	return func(ctx context.Context, container C, model M) (context.Context, M, Error) {
+		return ctx, model, NewError(err, http.StatusInternalServerError, "invalid plan")
+	}
+}
+
+// isInvalid reports if strategy was returned by invalid, all strategies returned by invalid share the same code.
+func isInvalid[C, M any](strategy Strategy[C, M]) bool {
+	return reflect.ValueOf(strategy).Pointer() == reflect.ValueOf(invalid[C, M](nil)).Pointer()
+}
+
+// invalidCondition returns a condition that is false. It is returned by And, Or and Not when they are given a nil
+// condition, and recognized by the helper functions that are given the condition.
+func invalidCondition[M any]() Condition[M] {
+	return func(M) bool {
+		return false
+	}
+}
+
+// isInvalidCondition reports if condition was returned by invalidCondition.
+func isInvalidCondition[M any](condition Condition[M]) bool {
+	return reflect.ValueOf(condition).Pointer() == reflect.ValueOf(invalidCondition[M]()).Pointer()
+}
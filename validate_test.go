@@ -0,0 +1,133 @@
+package speedrail_test
+
+import (
+	"context"
+	"github.com/Kansuler/speedrail"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type SpeedrailValidateTestSuite struct {
+	suite.Suite
+}
+
+func validateTestNoop(ctx context.Context, container any, model int) (context.Context, int, speedrail.Error) {
+	return ctx, model + 1, nil
+}
+
+func validateTestNoContext(ctx context.Context, container any, model int) (context.Context, int, speedrail.Error) {
+	return nil, model + 1, nil
+}
+
+func validateTestPositive(model int) bool {
+	return model > 0
+}
+
+func (suite *SpeedrailValidateTestSuite) TestValidate() {
+	suite.NoError(speedrail.Plan[any, int](validateTestNoop, speedrail.Group[any, int](validateTestNoop)).Validate())
+	suite.ErrorIs(speedrail.Speedrail[any, int](nil).Validate(), speedrail.ErrNoStrategy)
+
+	err := speedrail.Plan[any, int](
+		validateTestNoop,
+		speedrail.Group[any, int](nil, validateTestNoop),
+		nil,
+		speedrail.If[any, int](nil, validateTestNoop),
+		speedrail.IfElse[any, int](speedrail.And[int](validateTestPositive, nil), validateTestNoop, speedrail.Merge[any, int](nil)),
+	).Validate()
+	suite.ErrorIs(err, speedrail.ErrInvalidPlan)
+	suite.Equal("invalid plan: "+
+		"strategy 1: Group: strategy 0 is nil; "+
+		"strategy 2 is nil; "+
+		"strategy 3: If: condition is nil; "+
+		"strategy 4: IfElse: condition is given a nil condition; "+
+		"strategy 4: IfElse: onFalse: Merge: strategy 0 is nil", err.Error())
+}
+
+func (suite *SpeedrailValidateTestSuite) TestValidateHelpers() {
+	cache := speedrail.NewLRUCache[int](1)
+	key := func(int) string { return "key" }
+	apply := func(current, cached int) int { return cached }
+	get := func(model int) int { return model }
+	set := func(model int, part int) int { return part }
+
+	tests := map[string]struct {
+		strategy speedrail.Strategy[any, int]
+		expected string
+	}{
+		"MergeUsing":    {speedrail.MergeUsing[any, int](speedrail.DefaultMergePolicy, validateTestNoop, nil), "MergeUsing: strategy 1 is nil"},
+		"Hedge":         {speedrail.Hedge[any, int](time.Second, nil), "Hedge: strategy is nil"},
+		"Cached":        {speedrail.Cached[any, int](nil, key, apply, nil, time.Minute), "Cached: cache is nil; strategy 0: Cached: strategy is nil"},
+		"CachedKey":     {speedrail.Cached[any, int](cache, nil, nil, validateTestNoop, time.Minute), "Cached: key is nil; strategy 0: Cached: apply is nil"},
+		"Focus":         {speedrail.Focus[any, int, int]("part", get, set, speedrail.Plan[any, int](nil)), "Focus: plan: strategy 0 is nil"},
+		"FocusGet":      {speedrail.Focus[any, int, int]("part", nil, set, speedrail.Plan[any, int](validateTestNoop)), "Focus: get is nil"},
+		"WithContainer": {speedrail.WithContainer[any, any, int](nil, speedrail.Plan[any, int](validateTestNoop)), "WithContainer: adapt is nil"},
+		"AsStrategy":    {speedrail.Plan[any, int](nil).AsStrategy(speedrail.WithMiddleware[any, int](nil)), "AsStrategy: strategy 0 is nil; strategy 0: AsStrategy: middleware 0 is nil"},
+		"Use":           {speedrail.Strategy[any, int](validateTestNoop).Use(nil), "Use: middleware 0 is nil"},
+		"Func":          {speedrail.Func[any, int](nil), "Func: fn is nil"},
+		"Not":           {speedrail.If[any, int](speedrail.Not[int](speedrail.Or[int](nil)), validateTestNoop), "If: condition is given a nil condition"},
+	}
+
+	for name, test := range tests {
+		err := speedrail.Plan(test.strategy).Validate()
+		suite.ErrorIs(err, speedrail.ErrInvalidPlan, name)
+		suite.Equal("invalid plan: strategy 0: "+test.expected, err.Error(), name)
+	}
+}
+
+func (suite *SpeedrailValidateTestSuite) TestExecuteInvalidPlan() {
+	_, model, err := speedrail.Plan[any, int](validateTestNoop, speedrail.Group[any, int](nil), validateTestNoop).Execute(context.Background(), nil, 0)
+	suite.ErrorIs(err, speedrail.ErrInvalidPlan)
+	suite.Equal(http.StatusInternalServerError, err.StatusCode())
+	suite.Equal(1, model)
+
+	_, _, err = speedrail.Plan[any, int](validateTestNoop, nil).Execute(context.Background(), nil, 0)
+	suite.ErrorIs(err, speedrail.ErrInvalidPlan)
+}
+
+func (suite *SpeedrailValidateTestSuite) TestNoContextReturned() {
+	tests := map[string]speedrail.Strategy[any, int]{
+		"Group":  speedrail.Group[any, int](validateTestNoContext, validateTestNoop),
+		"Merge":  speedrail.Merge[any, int](validateTestNoContext, validateTestNoop),
+		"If":     speedrail.If[any, int](validateTestPositive, validateTestNoContext),
+		"IfElse": speedrail.IfElse[any, int](validateTestPositive, validateTestNoop, validateTestNoContext),
+	}
+
+	for name, strategy := range tests {
+		var continued bool
+		after := func(ctx context.Context, container any, model int) (context.Context, int, speedrail.Error) {
+			continued = true
+			return ctx, model, nil
+		}
+
+		model := 1
+		if name == "IfElse" {
+			model = 0
+		}
+
+		ctx, _, err := speedrail.Group[any, int](strategy, after)(context.Background(), nil, model)
+		suite.NotNil(ctx, name)
+		suite.ErrorIs(err, speedrail.ErrNoContextReturned, name)
+		suite.Equal(http.StatusInternalServerError, err.StatusCode(), name)
+		suite.False(continued, name)
+	}
+}
+
+func (suite *SpeedrailValidateTestSuite) TestNoContext() {
+	plan := speedrail.Plan[any, int](validateTestNoop)
+
+	ctx, model, err := plan.Execute(nil, nil, 1)
+	suite.Nil(ctx)
+	suite.Equal(1, model)
+	suite.ErrorIs(err, speedrail.ErrNoContext)
+	suite.Equal(http.StatusInternalServerError, err.StatusCode())
+
+	result := plan.Run(nil, nil, 1)
+	suite.Equal(1, result.Model)
+	suite.ErrorIs(result.Err, speedrail.ErrNoContext)
+}
+
+func TestSpeedrailValidateTestSuite(t *testing.T) {
+	suite.Run(t, new(SpeedrailValidateTestSuite))
+}
@@ -0,0 +1,84 @@
+package speedrail
+
+import (
+	"context"
+	"sync"
+)
+
+// Warning is a non-fatal annotation emitted by a strategy, such as "email domain looks disposable". Unlike an error, a
+// warning does not stop the execution of a plan.
+type Warning struct {
+	Code     string         `json:"code,omitempty"`
+	Field    string         `json:"field,omitempty"`
+	Message  string         `json:"message"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// warningCollector accumulates the warnings of an execution, strategies may emit warnings concurrently.
+type warningCollector struct {
+	mutex    sync.Mutex
+	warnings []Warning
+}
+
+// warningsKey is the context key of the warning collector.
+type warningsKey struct{}
+
+// withWarnings will return a context with a warning collector, unless the context already has one.
+func withWarnings(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(warningsKey{}).(*warningCollector); ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, warningsKey{}, &warningCollector{})
+}
+
+// forkWarnings will return a context with a warning collector of its own, for a strategy whose warnings are only kept
+// if its result is used, together with the collector. If ctx does not collect warnings it is returned as is, and nil.
+func forkWarnings(ctx context.Context) (context.Context, *warningCollector) {
+	if _, ok := ctx.Value(warningsKey{}).(*warningCollector); !ok {
+		return ctx, nil
+	}
+
+	collector := &warningCollector{}
+	return context.WithValue(ctx, warningsKey{}, collector), collector
+}
+
+// keepWarnings adds the warnings of a forked collector to the collector of ctx.
+func keepWarnings(ctx context.Context, forked *warningCollector) {
+	if forked == nil {
+		return
+	}
+
+	forked.mutex.Lock()
+	warnings := append([]Warning(nil), forked.warnings...)
+	forked.mutex.Unlock()
+
+	for _, warning := range warnings {
+		Warn(ctx, warning)
+	}
+}
+
+// Warn will emit a warning from a strategy. The warning is collected for the execution of the plan the context belongs
+// to, and is dropped if the context does not belong to an execution.
+func Warn(ctx context.Context, warning Warning) {
+	collector, ok := ctx.Value(warningsKey{}).(*warningCollector)
+	if !ok {
+		return
+	}
+
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+	collector.warnings = append(collector.warnings, warning)
+}
+
+// Warnings returns the warnings emitted during the execution of a plan, in order, given the context returned by Execute.
+func Warnings(ctx context.Context) []Warning {
+	collector, ok := ctx.Value(warningsKey{}).(*warningCollector)
+	if !ok {
+		return nil
+	}
+
+	collector.mutex.Lock()
+	defer collector.mutex.Unlock()
+	return append([]Warning(nil), collector.warnings...)
+}
@@ -0,0 +1,78 @@
+package speedrail_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Kansuler/speedrail"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type SpeedrailWarningTestSuite struct {
+	suite.Suite
+}
+
+type warningTestModel struct {
+	Email string
+}
+
+func warningTestDisposable(ctx context.Context, container any, model warningTestModel) (context.Context, warningTestModel, speedrail.Error) {
+	if strings.HasSuffix(model.Email, "@mailinator.com") {
+		speedrail.Warn(ctx, speedrail.Warning{Code: "DISPOSABLE_EMAIL", Field: "email", Message: "email domain looks disposable"})
+	}
+
+	return ctx, model, nil
+}
+
+func (suite *SpeedrailWarningTestSuite) TestWarnings() {
+	nested := speedrail.Plan[any, warningTestModel](
+		func(ctx context.Context, container any, model warningTestModel) (context.Context, warningTestModel, speedrail.Error) {
+			speedrail.Warn(ctx, speedrail.Warning{Message: "nested"})
+			return ctx, model, nil
+		},
+	)
+
+	plan := speedrail.Plan(
+		warningTestDisposable,
+		speedrail.Merge(
+			func(ctx context.Context, container any, model warningTestModel) (context.Context, warningTestModel, speedrail.Error) {
+				return nested.Execute(ctx, container, model)
+			},
+		),
+	)
+
+	ctx, model, err := plan.Execute(context.Background(), nil, warningTestModel{Email: "john@mailinator.com"})
+	suite.NoError(err)
+	suite.Equal("john@mailinator.com", model.Email)
+	suite.Equal([]speedrail.Warning{
+		{Code: "DISPOSABLE_EMAIL", Field: "email", Message: "email domain looks disposable"},
+		{Message: "nested"},
+	}, speedrail.Warnings(ctx))
+
+	ctx, _, err = plan.Execute(context.Background(), nil, warningTestModel{Email: "john@example.com"})
+	suite.NoError(err)
+	suite.Equal([]speedrail.Warning{{Message: "nested"}}, speedrail.Warnings(ctx))
+}
+
+func (suite *SpeedrailWarningTestSuite) TestWarningsOnError() {
+	plan := speedrail.Plan(
+		warningTestDisposable,
+		speedrail.ThrowError[any, warningTestModel](speedrail.NewError(errors.New("failed"), http.StatusBadRequest, "failed")),
+	)
+
+	ctx, _, err := plan.Execute(context.Background(), nil, warningTestModel{Email: "john@mailinator.com"})
+	suite.Error(err)
+	suite.Len(speedrail.Warnings(ctx), 1)
+}
+
+func (suite *SpeedrailWarningTestSuite) TestWarnOutsideExecution() {
+	ctx := context.Background()
+	speedrail.Warn(ctx, speedrail.Warning{Message: "dropped"})
+	suite.Nil(speedrail.Warnings(ctx))
+}
+
+func TestSpeedrailWarningTestSuite(t *testing.T) {
+	suite.Run(t, new(SpeedrailWarningTestSuite))
+}
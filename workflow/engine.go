@@ -0,0 +1,280 @@
+// Package workflow runs speedrail plans as durable workflows. Plans are registered by name, every execution gets an
+// ID, and the completion of each strategy is journaled so that executions interrupted by a restart can be resumed.
+package workflow
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/Kansuler/speedrail"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrPlanNotRegistered is the error returned when no plan is registered with the given name.
+var ErrPlanNotRegistered = errors.New("plan is not registered")
+
+// ErrPlanAlreadyRegistered is the error returned when a plan is registered with a name that is already taken.
+var ErrPlanAlreadyRegistered = errors.New("plan is already registered")
+
+// ErrExecutionNotFound is the error returned when there is no execution with the given ID.
+var ErrExecutionNotFound = errors.New("execution not found")
+
+// ErrExecutionNotRunning is the error returned when resuming an execution that has already completed or failed.
+var ErrExecutionNotRunning = errors.New("execution is not running")
+
+// Execution is the state of an execution, as recorded in the journal.
+type Execution[M any] struct {
+	ID         string
+	Plan       string
+	Status     Status
+	Completed  int
+	Model      M
+	Error      string
+	StatusCode int
+}
+
+// Engine executes registered plans durably. All plans in an engine share the same container and model types.
+type Engine[C, M any] struct {
+	mu        sync.RWMutex
+	journal   Journal
+	codec     speedrail.Codec[M]
+	container C
+	plans     map[string]speedrail.Speedrail[C, M]
+}
+
+// New will return an engine that journals executions to journal, with models serialized by codec.
+func New[C, M any](journal Journal, codec speedrail.Codec[M], container C) *Engine[C, M] {
+	return &Engine[C, M]{
+		journal:   journal,
+		codec:     codec,
+		container: container,
+		plans:     map[string]speedrail.Speedrail[C, M]{},
+	}
+}
+
+// Register makes plan available for execution under name. The name is recorded in the journal, so it must stay the same
+// across restarts for executions to be resumed.
+func (e *Engine[C, M]) Register(name string, plan speedrail.Speedrail[C, M]) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.plans[name]; ok {
+		return fmt.Errorf("%w: %s", ErrPlanAlreadyRegistered, name)
+	}
+
+	e.plans[name] = plan
+	return nil
+}
+
+// plan returns the plan registered with name.
+func (e *Engine[C, M]) plan(name string) (speedrail.Speedrail[C, M], bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	plan, ok := e.plans[name]
+	return plan, ok
+}
+
+// Start executes the plan registered with name, and returns the ID of the execution together with the result.
+func (e *Engine[C, M]) Start(ctx context.Context, name string, model M) (string, context.Context, M, speedrail.Error) {
+	if ctx == nil {
+		return "", ctx, model, noContextError()
+	}
+
+	plan, ok := e.plan(name)
+	if !ok {
+		return "", ctx, model, speedrail.NewError(fmt.Errorf("%w: %s", ErrPlanNotRegistered, name), http.StatusInternalServerError, "plan is not registered")
+	}
+
+	id, err := newExecutionID()
+	if err != nil {
+		return "", ctx, model, speedrail.NewError(err, http.StatusInternalServerError, "could not create execution id")
+	}
+
+	store := journalStore[M]{journal: e.journal, codec: e.codec, plan: name}
+	if err = store.Save(ctx, id, speedrail.Checkpoint[M]{Strategies: len(plan), Model: model}); err != nil {
+		return id, ctx, model, speedrail.NewError(errors.Join(speedrail.ErrCheckpointStore, err), http.StatusInternalServerError, "checkpoint store failed")
+	}
+
+	resultCtx, model, resultErr := plan.ExecuteWithCheckpoints(ctx, store, id, e.container, model)
+	return id, resultCtx, model, e.finish(ctx, store, id, len(plan), model, resultErr)
+}
+
+// Resume continues a running execution from its last journaled strategy.
+func (e *Engine[C, M]) Resume(ctx context.Context, executionID string) (context.Context, M, speedrail.Error) {
+	var model M
+	if ctx == nil {
+		return ctx, model, noContextError()
+	}
+
+	entry, ok, err := e.journal.Latest(ctx, executionID)
+	if err != nil {
+		return ctx, model, speedrail.NewError(errors.Join(speedrail.ErrCheckpointStore, err), http.StatusInternalServerError, "checkpoint store failed")
+	}
+
+	if !ok {
+		return ctx, model, speedrail.NewError(fmt.Errorf("%w: %s", ErrExecutionNotFound, executionID), http.StatusNotFound, "execution not found")
+	}
+
+	if entry.Status != StatusRunning {
+		return ctx, model, speedrail.NewError(fmt.Errorf("%w: %s", ErrExecutionNotRunning, executionID), http.StatusConflict, "execution is not running")
+	}
+
+	plan, ok := e.plan(entry.Plan)
+	if !ok {
+		return ctx, model, speedrail.NewError(fmt.Errorf("%w: %s", ErrPlanNotRegistered, entry.Plan), http.StatusInternalServerError, "plan is not registered")
+	}
+
+	store := journalStore[M]{journal: e.journal, codec: e.codec, plan: entry.Plan}
+	resultCtx, model, resultErr := plan.Resume(ctx, store, executionID, e.container)
+	return resultCtx, model, e.finish(ctx, store, executionID, len(plan), model, resultErr)
+}
+
+// Recover resumes every running execution of a registered plan, it should be called once when the process starts and
+// before any executions are started. The outcome of each execution is recorded in the journal, and only errors that
+// prevented an execution from being journaled are returned.
+func (e *Engine[C, M]) Recover(ctx context.Context) error {
+	running, err := e.journal.Running(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, entry := range running {
+		if _, ok := e.plan(entry.Plan); !ok {
+			continue
+		}
+
+		if _, _, err := e.Resume(ctx, entry.ExecutionID); err != nil && errors.Is(err, speedrail.ErrCheckpointStore) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Execution returns the state of the execution with id, and false if there is none.
+func (e *Engine[C, M]) Execution(ctx context.Context, id string) (Execution[M], bool, error) {
+	entry, ok, err := e.journal.Latest(ctx, id)
+	if err != nil || !ok {
+		return Execution[M]{}, false, err
+	}
+
+	model, err := e.codec.Unmarshal(entry.Model)
+	if err != nil {
+		return Execution[M]{}, false, err
+	}
+
+	return Execution[M]{
+		ID:         entry.ExecutionID,
+		Plan:       entry.Plan,
+		Status:     entry.Status,
+		Completed:  entry.Completed,
+		Model:      model,
+		Error:      entry.Error,
+		StatusCode: entry.StatusCode,
+	}, true, nil
+}
+
+// noContextError will return the error for an execution that is started or resumed with a nil context.
+func noContextError() speedrail.Error {
+	return speedrail.NewError(speedrail.ErrNoContext, http.StatusInternalServerError, "no context given to plan")
+}
+
+// finish journals the outcome of an execution. An execution that was interrupted because ctx is done is left running,
+// so that it is resumed after a restart.
+func (e *Engine[C, M]) finish(ctx context.Context, store journalStore[M], id string, strategies int, model M, resultErr speedrail.Error) speedrail.Error {
+	if resultErr != nil && (ctx.Err() != nil || errors.Is(resultErr, speedrail.ErrCheckpointStore)) {
+		return resultErr
+	}
+
+	entry := Entry{ExecutionID: id, Plan: store.plan, Status: StatusCompleted, Completed: strategies, Strategies: strategies}
+	if resultErr != nil {
+		latest, _, err := store.journal.Latest(ctx, id)
+		if err != nil {
+			return resultErr.Merge(speedrail.NewError(errors.Join(speedrail.ErrCheckpointStore, err), http.StatusInternalServerError, "checkpoint store failed"))
+		}
+
+		entry.Status = StatusFailed
+		entry.Completed = latest.Completed
+		entry.Error = resultErr.Error()
+		entry.StatusCode = resultErr.StatusCode()
+	}
+
+	if err := store.append(ctx, entry, model); err != nil {
+		storeErr := speedrail.NewError(errors.Join(speedrail.ErrCheckpointStore, err), http.StatusInternalServerError, "checkpoint store failed")
+		if resultErr != nil {
+			return resultErr.Merge(storeErr)
+		}
+
+		return storeErr
+	}
+
+	return resultErr
+}
+
+// journalStore adapts the journal to a speedrail.CheckpointStore for executions of a plan.
+type journalStore[M any] struct {
+	journal Journal
+	codec   speedrail.Codec[M]
+	plan    string
+}
+
+// Type check that journalStore implements speedrail.CheckpointStore interface
+var _ speedrail.CheckpointStore[any] = journalStore[any]{}
+
+// append serializes model and appends entry to the journal.
+func (s journalStore[M]) append(ctx context.Context, entry Entry, model M) error {
+	data, err := s.codec.Marshal(model)
+	if err != nil {
+		return err
+	}
+
+	entry.Model = data
+	entry.Time = time.Now()
+	return s.journal.Append(ctx, entry)
+}
+
+// Save journals the checkpoint as a running entry.
+func (s journalStore[M]) Save(ctx context.Context, id string, checkpoint speedrail.Checkpoint[M]) error {
+	return s.append(ctx, Entry{
+		ExecutionID: id,
+		Plan:        s.plan,
+		Status:      StatusRunning,
+		Completed:   checkpoint.Completed,
+		Strategies:  checkpoint.Strategies,
+	}, checkpoint.Model)
+}
+
+// Load returns the checkpoint of the latest journal entry.
+func (s journalStore[M]) Load(ctx context.Context, id string) (speedrail.Checkpoint[M], bool, error) {
+	entry, ok, err := s.journal.Latest(ctx, id)
+	if err != nil || !ok {
+		return speedrail.Checkpoint[M]{}, false, err
+	}
+
+	model, err := s.codec.Unmarshal(entry.Model)
+	if err != nil {
+		return speedrail.Checkpoint[M]{}, false, err
+	}
+
+	return speedrail.Checkpoint[M]{Completed: entry.Completed, Strategies: entry.Strategies, Model: model}, true, nil
+}
+
+// Delete does nothing, the completion of an execution is journaled by the engine.
+func (s journalStore[M]) Delete(context.Context, string) error {
+	return nil
+}
+
+// newExecutionID will return a random execution ID.
+func newExecutionID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(id), nil
+}
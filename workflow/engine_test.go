@@ -0,0 +1,138 @@
+package workflow_test
+
+import (
+	"context"
+	"errors"
+	"github.com/Kansuler/speedrail"
+	"github.com/Kansuler/speedrail/workflow"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+type WorkflowEngineTestSuite struct {
+	suite.Suite
+}
+
+type engineTestModel struct {
+	Steps []string `json:"steps"`
+}
+
+type engineTestContainer struct {
+	// crash cancels the context of the execution in the second step, simulating a shutdown.
+	crash context.CancelFunc
+}
+
+func engineTestStep(name string) speedrail.Strategy[*engineTestContainer, engineTestModel] {
+	return func(ctx context.Context, container *engineTestContainer, model engineTestModel) (context.Context, engineTestModel, speedrail.Error) {
+		if name == "second" && container.crash != nil {
+			container.crash()
+			return ctx, model, speedrail.NewError(ctx.Err(), http.StatusServiceUnavailable, "shutting down")
+		}
+
+		model.Steps = append(model.Steps, name)
+		return ctx, model, nil
+	}
+}
+
+func engineTestPlan() speedrail.Speedrail[*engineTestContainer, engineTestModel] {
+	return speedrail.Plan(
+		engineTestStep("first"),
+		engineTestStep("second"),
+		engineTestStep("third"),
+	)
+}
+
+func (suite *WorkflowEngineTestSuite) TestStart() {
+	engine := workflow.New[*engineTestContainer, engineTestModel](workflow.NewMemoryJournal(), speedrail.JSONCodec[engineTestModel]{}, &engineTestContainer{})
+	suite.NoError(engine.Register("signup", engineTestPlan()))
+	suite.ErrorIs(engine.Register("signup", engineTestPlan()), workflow.ErrPlanAlreadyRegistered)
+
+	id, _, model, err := engine.Start(context.Background(), "signup", engineTestModel{})
+	suite.NoError(err)
+	suite.NotEmpty(id)
+	suite.Equal([]string{"first", "second", "third"}, model.Steps)
+
+	execution, ok, loadErr := engine.Execution(context.Background(), id)
+	suite.NoError(loadErr)
+	suite.True(ok)
+	suite.Equal(workflow.StatusCompleted, execution.Status)
+	suite.Equal(3, execution.Completed)
+	suite.Equal(model, execution.Model)
+
+	_, _, _, err = engine.Start(context.Background(), "unknown", engineTestModel{})
+	suite.ErrorIs(err, workflow.ErrPlanNotRegistered)
+}
+
+func (suite *WorkflowEngineTestSuite) TestFailed() {
+	engine := workflow.New[any, engineTestModel](workflow.NewMemoryJournal(), speedrail.JSONCodec[engineTestModel]{}, nil)
+	suite.NoError(engine.Register("fail", speedrail.Plan[any, engineTestModel](
+		speedrail.ThrowError[any, engineTestModel](speedrail.NewError(errors.New("invalid"), http.StatusBadRequest, "invalid")),
+	)))
+
+	id, _, _, err := engine.Start(context.Background(), "fail", engineTestModel{})
+	suite.Error(err)
+
+	execution, ok, loadErr := engine.Execution(context.Background(), id)
+	suite.NoError(loadErr)
+	suite.True(ok)
+	suite.Equal(workflow.StatusFailed, execution.Status)
+	suite.Equal("invalid", execution.Error)
+	suite.Equal(http.StatusBadRequest, execution.StatusCode)
+
+	_, _, err = engine.Resume(context.Background(), id)
+	suite.ErrorIs(err, workflow.ErrExecutionNotRunning)
+}
+
+func (suite *WorkflowEngineTestSuite) TestNoContext() {
+	engine := workflow.New[*engineTestContainer, engineTestModel](workflow.NewMemoryJournal(), speedrail.JSONCodec[engineTestModel]{}, &engineTestContainer{})
+	suite.NoError(engine.Register("signup", engineTestPlan()))
+
+	id, _, _, err := engine.Start(nil, "signup", engineTestModel{})
+	suite.Empty(id)
+	suite.ErrorIs(err, speedrail.ErrNoContext)
+	suite.Equal(http.StatusInternalServerError, err.StatusCode())
+
+	_, _, err = engine.Resume(nil, "unknown")
+	suite.ErrorIs(err, speedrail.ErrNoContext)
+}
+
+func (suite *WorkflowEngineTestSuite) TestRecoverAfterRestart() {
+	path := filepath.Join(suite.T().TempDir(), "journal.log")
+	journal, err := workflow.OpenFileJournal(path)
+	suite.Require().NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	engine := workflow.New[*engineTestContainer, engineTestModel](journal, speedrail.JSONCodec[engineTestModel]{}, &engineTestContainer{crash: cancel})
+	suite.NoError(engine.Register("signup", engineTestPlan()))
+
+	id, _, _, startErr := engine.Start(ctx, "signup", engineTestModel{})
+	suite.Error(startErr)
+	suite.NoError(journal.Close())
+
+	journal, err = workflow.OpenFileJournal(path)
+	suite.Require().NoError(err)
+	defer journal.Close()
+
+	engine = workflow.New[*engineTestContainer, engineTestModel](journal, speedrail.JSONCodec[engineTestModel]{}, &engineTestContainer{})
+	suite.NoError(engine.Register("signup", engineTestPlan()))
+
+	execution, ok, err := engine.Execution(context.Background(), id)
+	suite.NoError(err)
+	suite.True(ok)
+	suite.Equal(workflow.StatusRunning, execution.Status)
+	suite.Equal(1, execution.Completed)
+
+	suite.NoError(engine.Recover(context.Background()))
+
+	execution, ok, err = engine.Execution(context.Background(), id)
+	suite.NoError(err)
+	suite.True(ok)
+	suite.Equal(workflow.StatusCompleted, execution.Status)
+	suite.Equal([]string{"first", "second", "third"}, execution.Model.Steps)
+}
+
+func TestWorkflowEngineTestSuite(t *testing.T) {
+	suite.Run(t, new(WorkflowEngineTestSuite))
+}
@@ -0,0 +1,178 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status is the status of an execution.
+type Status string
+
+const (
+	// StatusRunning is the status of an execution that has not finished, and should be resumed after a restart.
+	StatusRunning Status = "running"
+	// StatusCompleted is the status of an execution where all strategies completed.
+	StatusCompleted Status = "completed"
+	// StatusFailed is the status of an execution where a strategy returned an error.
+	StatusFailed Status = "failed"
+)
+
+// Entry is a record in the journal, describing the state of an execution after a strategy has completed.
+type Entry struct {
+	ExecutionID string    `json:"execution_id"`
+	Plan        string    `json:"plan"`
+	Status      Status    `json:"status"`
+	Completed   int       `json:"completed"`
+	Strategies  int       `json:"strategies"`
+	Model       []byte    `json:"model,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Time        time.Time `json:"time"`
+}
+
+// Journal records the progress of executions, so that they can be resumed after a restart.
+type Journal interface {
+	// Append records an entry, the latest entry for an execution describes its current state.
+	Append(ctx context.Context, entry Entry) error
+	// Latest returns the latest entry for an execution, and false if there is none.
+	Latest(ctx context.Context, executionID string) (Entry, bool, error)
+	// Running returns the latest entry of every execution that is still running.
+	Running(ctx context.Context) ([]Entry, error)
+}
+
+// index keeps the latest entry of every execution.
+type index struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+// set stores entry as the latest entry of its execution.
+func (i *index) set(entry Entry) {
+	i.entries[entry.ExecutionID] = entry
+}
+
+// Latest returns the latest entry for an execution, and false if there is none.
+func (i *index) Latest(_ context.Context, executionID string) (Entry, bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	entry, ok := i.entries[executionID]
+	return entry, ok, nil
+}
+
+// Running returns the latest entry of every execution that is still running, in the order they were last updated.
+func (i *index) Running(_ context.Context) ([]Entry, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var running []Entry
+	for _, entry := range i.entries {
+		if entry.Status == StatusRunning {
+			running = append(running, entry)
+		}
+	}
+
+	sort.Slice(running, func(a, b int) bool {
+		return running[a].Time.Before(running[b].Time)
+	})
+
+	return running, nil
+}
+
+// MemoryJournal is an in-memory Journal, it does not survive a restart and is mostly useful in tests.
+type MemoryJournal struct {
+	index
+}
+
+// Type check that MemoryJournal implements Journal interface
+var _ Journal = &MemoryJournal{}
+
+// NewMemoryJournal will return an in-memory journal.
+func NewMemoryJournal() *MemoryJournal {
+	return &MemoryJournal{index: index{entries: map[string]Entry{}}}
+}
+
+// Append records an entry, the latest entry for an execution describes its current state.
+func (j *MemoryJournal) Append(_ context.Context, entry Entry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.set(entry)
+	return nil
+}
+
+// FileJournal is a Journal that appends entries as JSON lines to a file. The file is replayed when it is opened.
+type FileJournal struct {
+	index
+	file *os.File
+}
+
+// Type check that FileJournal implements Journal interface
+var _ Journal = &FileJournal{}
+
+// OpenFileJournal will open, or create, the journal file at path and replay its entries.
+func OpenFileJournal(path string) (*FileJournal, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	journal := &FileJournal{index: index{entries: map[string]Entry{}}, file: file}
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		var entry Entry
+		if err = json.Unmarshal(line, &entry); err != nil {
+			// A partially written line is the result of a crash while appending, and is ignored.
+			continue
+		}
+
+		journal.set(entry)
+	}
+
+	// Terminate a partially written line, so that it does not corrupt the next entry.
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		if _, err = file.Write([]byte{'\n'}); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	return journal, nil
+}
+
+// Append records an entry, the latest entry for an execution describes its current state. The entry is synced to disk
+// before Append returns.
+func (j *FileJournal) Append(_ context.Context, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err = j.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	if err = j.file.Sync(); err != nil {
+		return err
+	}
+
+	j.set(entry)
+	return nil
+}
+
+// Close closes the journal file.
+func (j *FileJournal) Close() error {
+	return j.file.Close()
+}